@@ -2,31 +2,65 @@ package app
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
-	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
 
 	cont "github.com/sarulabs/di"
-	"github.com/spf13/viper"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"grpoc/modules"
-	"grpoc/services/todo"
 )
 
 const (
-	ConfigKeyAppPort  = "app.port"
+	ConfigKeyAppPort    = modules.ConfigKeyAppPort
+	ConfigKeyReflection = modules.ConfigKeyReflection
 )
 
 type App struct {
-	server    *grpc.Server
-	container cont.Container
+	server        *grpc.Server
+	container     cont.Container
+	ownsContainer bool
+	logger        *zap.Logger
+	interceptors  []Interceptor
+	health        *healthChecker
+	configSource  modules.ConfigSource
+	listener      net.Listener
+	serverOptions []grpc.ServerOption
+	registry      *Registry
+	services      []Service
+	running       []Service
+
+	// shutdown and stop are closed to end Run gracefully or at once, done is closed once Run returned
+	shutdown     chan struct{}
+	stop         chan struct{}
+	done         chan struct{}
+	shutdownOnce sync.Once
+	stopOnce     sync.Once
 }
 
-// NewApp - Creates a new application
-func NewApp() (app *App) {
-	app = &App{}
+// NewApp - Creates a new application, configured by the options
+func NewApp(opts ...Option) (app *App) {
+	app = &App{
+		ownsContainer: true,
+		registry:      DefaultRegistry,
+		shutdown:      make(chan struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(app)
+	}
+
 	return
 }
 
@@ -36,49 +70,221 @@ func (app *App) GetServer() *grpc.Server {
 }
 
 // Run - Run prepare the application and start the grpc server.
-// It initializes the container with the resource like database, app config , logger etc. and registers the rpc services
+// It initializes the container with the resource like database, app config , logger etc. and registers the rpc services.
+// It returns nil once SIGINT, SIGTERM, the end of ctx, Shutdown or Stop stopped the server, and the resources
+// of the container are released. Run is called once
 func (app *App) Run(ctx context.Context) (err error) {
 	var (
-		listen net.Listener
+		listen = app.listener
+		opts   []grpc.ServerOption
 	)
 
-	app.server = grpc.NewServer()
+	defer close(app.done)
+
+	// registered first so a signal during the start drains the server once it runs, if the start goes through
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	// a stop during the start gives up the builds waiting on something, like the database ping retries
+	start, cancelStart := context.WithCancel(ctx)
+	defer cancelStart()
+	startSignals := make(chan os.Signal, 1)
+	signal.Notify(startSignals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(startSignals)
+	go func() {
+		select {
+		case <-startSignals:
+		case <-app.shutdown:
+		case <-app.stop:
+		case <-start.Done():
+		}
+		cancelStart()
+	}()
 
-	app.container, err = modules.InitContainer()
+	if app.container == nil {
+		if app.container, err = modules.InitContainer(start, app.configSource); err != nil {
+			return
+		}
+	}
+	defer app.close()
+
+	// an invalid config fails here, the logger is the first resource reading it
+	logger, err := app.container.SafeGet(modules.InstLogger)
 	if err != nil {
 		return
 	}
+	app.logger = logger.(*zap.Logger)
 
-	app.registerServices()
+	store := app.container.Get(modules.InstConfigStore).(*modules.ConfigStore)
+	if file := store.Config().ConfigFileUsed(); file != "" {
+		app.logger.Info("Loaded config", zap.String("file", file))
+	} else {
+		app.logger.Info("No config file found, using the defaults and the environment", zap.String("path", store.Path()))
+	}
+	watcher, err := app.container.SafeGet(modules.InstWatcher)
+	if err != nil {
+		return
+	}
+	if err = store.Watch(watcher.(*modules.FileWatcher), app.logger); err != nil {
+		return
+	}
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	// connect first, the database is pinged with retries and an unreachable one stops the start here
+	if _, err = app.container.SafeGet(modules.InstDatabase); err != nil {
+		if start.Err() != nil {
+			app.logger.Info("Start cancelled while connecting to the database", zap.Error(err))
+			return nil
+		}
+		app.logger.Error("Failed to start, database unavailable", zap.Error(err))
+		return
+	}
+
+	if opts, err = app.serverInterceptors(); err != nil {
+		return
+	}
+
+	config := app.container.Get(modules.InstConfig).(*modules.AppConfig)
+	if config.TLS.Enabled {
+		var reloader interface{}
+		if reloader, err = app.container.SafeGet(modules.InstTLS); err != nil {
+			return
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(reloader.(*modules.TLSReloader).Config())))
+		app.logger.Info("TLS enabled", zap.Bool("mutual", config.TLS.ClientCA != ""))
+	}
+
+	if app.server, err = newServer(append(opts, app.serverOptions...)); err != nil {
+		return
+	}
+
+	app.health = newHealthChecker(
+		app.container.Get(modules.InstDatabase).(*sql.DB),
+		app.logger,
+		config.Health.Interval,
+		config.Health.Timeout,
+	)
+
+	if err = app.registerServices(config); err != nil {
+		return
+	}
+	app.health.start()
+
+	if config.Metrics.Enabled {
+		if err = app.serveMetrics(config.Metrics.Port); err != nil {
+			return
+		}
+	}
+
+	// SIGHUP reloads the config, the reload logs the changed keys or why it was rejected
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	defer func() {
+		signal.Stop(hup)
+		close(hup)
+	}()
 
 	go func() {
-		for range c {
-			log.Println("Shutting down grpc server...")
-			app.server.GracefulStop()
-			<-ctx.Done()
+		for range hup {
+			app.logger.Info("Reloading config on SIGHUP")
+			_, _ = store.Reload()
 		}
 	}()
 
-	port := app.container.Get(modules.InstAppConfig).(*viper.Viper).Get(ConfigKeyAppPort).(int)
-	listen, err = net.Listen("tcp", ":"+ fmt.Sprint(port))
-	if err != nil {
+	// started, the stop requests are handled by the drain from here
+	signal.Stop(startSignals)
+	cancelStart()
+
+	if listen == nil {
+		if listen, err = net.Listen("tcp", ":"+fmt.Sprint(config.Port)); err != nil {
+			return
+		}
+	}
+
+	app.logger.Info("Starting gRPC server", zap.String("addr", listen.Addr().String()))
+
+	served := make(chan error, 1)
+	go func() {
+		served <- app.server.Serve(listen)
+	}()
+
+	select {
+	case sig := <-signals:
+		app.logger.Info("Shutting down grpc server...", zap.Stringer("signal", sig))
+		app.drain(config.Shutdown.Timeout)
+	case <-ctx.Done():
+		app.logger.Info("Shutting down grpc server...", zap.NamedError("reason", ctx.Err()))
+		app.drain(config.Shutdown.Timeout)
+	case <-app.shutdown:
+		app.logger.Info("Shutting down grpc server...")
+		app.drain(config.Shutdown.Timeout)
+	case <-app.stop:
+		app.logger.Info("Stopping grpc server")
+		app.health.shutdown()
+		app.server.Stop()
+	case err = <-served:
+		app.logger.Error("gRPC server stopped", zap.Error(err))
 		return
 	}
 
-	log.Printf("Starting gRPC server at port %d", port)
+	// a stop requested before Serve started stops it right away
+	if err = <-served; err == grpc.ErrServerStopped {
+		err = nil
+	}
+
+	return
+}
+
+// serveMetrics - Serve the prometheus metrics on their own port in the background.
+// The port is opened first, a port in use fails the start like an unreachable database
+func (app *App) serveMetrics(port int) error {
+	i, err := app.container.SafeGet(modules.InstMetrics)
+	if err != nil {
+		return err
+	}
+	metrics := i.(*modules.Metrics)
+
+	lis, err := metrics.Listen(port)
+	if err != nil {
+		return fmt.Errorf("failed to serve metrics-> %v", err)
+	}
+
+	go func() {
+		if err := metrics.Serve(lis); err != nil && err != http.ErrServerClosed {
+			app.logger.Error("Serving metrics failed", zap.Error(err))
+		}
+	}()
 
-	return app.server.Serve(listen)
+	app.logger.Info("Serving metrics", zap.Int("port", port))
 
+	return nil
 }
 
-// registerServices - Register rpc services with the app grpc server
-func (app *App) registerServices() {
+// newServer - Create the grpc server. grpc panics on an interceptor set twice, e.g. by an option of WithServerOptions,
+// it is returned as an error
+func newServer(opts []grpc.ServerOption) (server *grpc.Server, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid grpc server options, add interceptors with WithUnaryInterceptors and WithStreamInterceptors-> %v", r)
+		}
+	}()
 
-	todo.RegisterToDoServiceServer(app.server, todo.NewToDoServiceServer(&app.container))
+	return grpc.NewServer(opts...), nil
 }
 
+// registerServices - Register the enabled services, the health and the reflection services with the app grpc server
+func (app *App) registerServices(config *modules.AppConfig) (err error) {
+	if err = app.startServices(config); err != nil {
+		return
+	}
 
+	healthpb.RegisterHealthServer(app.server, app.health.server)
 
+	if config.Reflection {
+		reflection.Register(app.server)
+		app.logger.Info("Server reflection enabled")
+	}
+
+	return
+}
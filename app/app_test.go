@@ -0,0 +1,356 @@
+package app
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	cont "github.com/sarulabs/di"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"grpoc/modules"
+)
+
+// testService answers the empty calls, the other methods are not implemented
+type testService struct {
+	testpb.TestServiceServer
+}
+
+func (s *testService) EmptyCall(ctx context.Context, req *testpb.Empty) (*testpb.Empty, error) {
+	return &testpb.Empty{}, nil
+}
+
+// testContainer - Container of the app resources with a stub database, the config has no file
+func testContainer(t *testing.T, overrides modules.ConfigOverrides) cont.Container {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := modules.ConfigSource{
+		Path:      dir,
+		Overrides: modules.ConfigOverrides{ConfigKeyAppPort: "1", modules.ConfigKeyLogLevel: "error", ConfigKeyInterceptors: "requestid recovery"},
+	}
+	for k, v := range overrides {
+		source.Overrides[k] = v
+	}
+
+	return newTestContainer(t, source)
+}
+
+// newTestContainer - Container of the app resources with a stub database, the config is read from the source
+func newTestContainer(t *testing.T, source modules.ConfigSource) cont.Container {
+	t.Helper()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder, err := cont.NewBuilder()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, init := range []func(*cont.Builder) error{
+		func(b *cont.Builder) error { return modules.InitAppConfig(b, source) },
+		modules.InitConfig,
+		modules.InitLogger,
+		modules.InitWatcher,
+		modules.InitAuth,
+		modules.InitAuthz,
+		modules.InitMetrics,
+	} {
+		if err = init(builder); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = builder.Add(cont.Def{
+		Name:  modules.InstDatabase,
+		Scope: cont.App,
+		Build: func(ctn cont.Container) (interface{}, error) {
+			return db, nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return builder.Build()
+}
+
+// dialTest - Client connection to the listener
+func dialTest(t *testing.T, listen *bufconn.Listener) *grpc.ClientConn {
+	t.Helper()
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+		return listen.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func TestApp_Run(t *testing.T) {
+	tests := []struct {
+		name string
+		stop func(app *App, cancel context.CancelFunc)
+	}{
+		{name: "Context cancelled", stop: func(app *App, cancel context.CancelFunc) { cancel() }},
+		{name: "Shutdown", stop: func(app *App, cancel context.CancelFunc) {
+			if err := app.Shutdown(context.Background()); err != nil {
+				t.Errorf("Shutdown() error = %v", err)
+			}
+		}},
+		{name: "Stop", stop: func(app *App, cancel context.CancelFunc) { app.Stop() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctn := testContainer(t, nil)
+			defer ctn.Delete()
+
+			listen := bufconn.Listen(1024 * 1024)
+			app := NewApp(
+				WithContainer(ctn),
+				WithListener(listen),
+				WithServerOptions(grpc.MaxRecvMsgSize(1024*1024)),
+				WithServices(NewService("test", func(server *grpc.Server, container cont.Container) {
+					testpb.RegisterTestServiceServer(server, &testService{})
+				})),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ran := make(chan error, 1)
+			go func() {
+				ran <- app.Run(ctx)
+			}()
+
+			conn := dialTest(t, listen)
+			defer conn.Close()
+
+			callCtx, callCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer callCancel()
+			if _, err := testpb.NewTestServiceClient(conn).EmptyCall(callCtx, &testpb.Empty{}, grpc.WaitForReady(true)); err != nil {
+				t.Fatalf("EmptyCall() error = %v", err)
+			}
+
+			tt.stop(app, cancel)
+
+			select {
+			case err := <-ran:
+				if err != nil {
+					t.Errorf("Run() error = %v, want nil once stopped", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Run() did not return")
+			}
+		})
+	}
+}
+
+func TestApp_Run_cancelStart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// nothing listens on port 1, the ping would retry for minutes
+	app := NewApp(WithConfigSource(modules.ConfigSource{
+		Path: dir,
+		Overrides: modules.ConfigOverrides{
+			modules.ConfigKeyLogLevel:         "error",
+			ConfigKeyInterceptors:             "requestid",
+			modules.ConfigKeyDbHost:           "127.0.0.1",
+			modules.ConfigKeyDbPort:           "1",
+			modules.ConfigKeyDbPingAttempts:   "100",
+			modules.ConfigKeyDbPingBackoff:    "1s",
+			modules.ConfigKeyDbPingMaxBackoff: "1s",
+		},
+	}))
+
+	ran := make(chan error, 1)
+	go func() {
+		ran <- app.Run(context.Background())
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = app.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v, want the start given up", err)
+	}
+	if err = <-ran; err != nil {
+		t.Errorf("Run() error = %v, want nil once stopped", err)
+	}
+}
+
+func TestApp_Run_defaultInterceptors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// nothing sets app.interceptors, an environment variable left empty does not clear them
+	os.Setenv("GRPOC_APP_INTERCEPTORS", "")
+	defer os.Unsetenv("GRPOC_APP_INTERCEPTORS")
+
+	source := modules.ConfigSource{
+		Path:      dir,
+		Overrides: modules.ConfigOverrides{ConfigKeyAppPort: "1", modules.ConfigKeyLogLevel: "error"},
+	}
+
+	// without a provider nor a policy the server does not start
+	invalid := newTestContainer(t, source)
+	defer invalid.Delete()
+
+	err = NewApp(WithContainer(invalid), WithListener(bufconn.Listen(1024))).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no auth provider") || !strings.Contains(err.Error(), modules.ConfigKeyAuthzPolicy) {
+		t.Fatalf("Run() error = %v, want the missing auth provider and policy", err)
+	}
+	if strings.Count(err.Error(), "invalid config:") != 1 || strings.Contains(err.Error(), "panicked") {
+		t.Errorf("Run() error = %v, want the problems reported once without a panic", err)
+	}
+
+	policy := "rules:\n  - method: /grpc.testing.TestService/*\n    roles: [viewer]\n"
+	if err = ioutil.WriteFile(filepath.Join(dir, "policy.yaml"), []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := "app:\n  authz:\n    policy: " + filepath.Join(dir, "policy.yaml") + "\n  auth:\n    apikeys:\n      - key: test-key\n        subject: tester\n        roles: [viewer]\n"
+	if err = ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctn := newTestContainer(t, source)
+	defer ctn.Delete()
+
+	listen := bufconn.Listen(1024 * 1024)
+	app := NewApp(
+		WithContainer(ctn),
+		WithListener(listen),
+		WithServices(NewService("test", func(server *grpc.Server, container cont.Container) {
+			testpb.RegisterTestServiceServer(server, &testService{})
+		})),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan error, 1)
+	go func() {
+		ran <- app.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-ran
+	}()
+
+	conn := dialTest(t, listen)
+	defer conn.Close()
+
+	callCtx, callCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer callCancel()
+
+	client := testpb.NewTestServiceClient(conn)
+	if _, err = client.EmptyCall(callCtx, &testpb.Empty{}, grpc.WaitForReady(true)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("EmptyCall() without credentials error = %v, want %v", err, codes.Unauthenticated)
+	}
+
+	keyCtx := metadata.AppendToOutgoingContext(callCtx, modules.MetadataKeyAPIKey, "test-key")
+	if _, err = client.EmptyCall(keyCtx, &testpb.Empty{}); err != nil {
+		t.Errorf("EmptyCall() with the api key error = %v", err)
+	}
+}
+
+func TestApp_Run_interceptorOptions(t *testing.T) {
+	noop := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+
+	// the app installs its own chain, grpc takes one unary interceptor
+	ctn := testContainer(t, nil)
+	defer ctn.Delete()
+
+	err := NewApp(WithContainer(ctn), WithListener(bufconn.Listen(1024)), WithServerOptions(grpc.UnaryInterceptor(noop))).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "WithUnaryInterceptors") {
+		t.Fatalf("Run() error = %v, want the interceptor option refused", err)
+	}
+
+	// the interceptors of the options join the chain
+	ctn = testContainer(t, nil)
+	defer ctn.Delete()
+
+	var called []string
+	listen := bufconn.Listen(1024 * 1024)
+	app := NewApp(
+		WithContainer(ctn),
+		WithListener(listen),
+		WithUnaryInterceptors(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			called = append(called, info.FullMethod)
+			return handler(ctx, req)
+		}),
+		WithServices(NewService("test", func(server *grpc.Server, container cont.Container) {
+			testpb.RegisterTestServiceServer(server, &testService{})
+		})),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ran := make(chan error, 1)
+	go func() {
+		ran <- app.Run(ctx)
+	}()
+
+	conn := dialTest(t, listen)
+	defer conn.Close()
+
+	callCtx, callCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer callCancel()
+	if _, err = testpb.NewTestServiceClient(conn).EmptyCall(callCtx, &testpb.Empty{}, grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("EmptyCall() error = %v", err)
+	}
+	if len(called) != 1 || called[0] != "/grpc.testing.TestService/EmptyCall" {
+		t.Errorf("interceptor called for %v, want the empty call", called)
+	}
+
+	cancel()
+	if err = <-ran; err != nil {
+		t.Errorf("Run() error = %v, want nil once stopped", err)
+	}
+}
+
+func TestApp_serveMetrics_portInUse(t *testing.T) {
+	taken, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	ctn := testContainer(t, nil)
+	defer ctn.Delete()
+
+	app := NewApp(WithContainer(ctn))
+	app.logger = zap.NewNop()
+
+	if err = app.serveMetrics(taken.Addr().(*net.TCPAddr).Port); err == nil {
+		t.Error("serveMetrics() error = nil, want an error for the port in use")
+	}
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"grpoc/modules"
+)
+
+const (
+	ConfigKeyHealthInterval = modules.ConfigKeyHealthInterval
+	ConfigKeyHealthTimeout  = modules.ConfigKeyHealthTimeout
+
+	DefaultHealthInterval = modules.DefaultHealthInterval
+	DefaultHealthTimeout  = modules.DefaultHealthTimeout
+)
+
+// healthChecker keeps the status of the grpc health service in line with the database reachability,
+// and the checks of the services having one
+type healthChecker struct {
+	server   *health.Server
+	db       *sql.DB
+	services []string
+	checks   map[string]func(ctx context.Context) error
+	failing  map[string]bool
+	interval time.Duration
+	timeout  time.Duration
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	probed  bool
+	serving bool
+	stopped bool
+	stop    chan struct{}
+}
+
+// newHealthChecker - Create the checker reporting the status of the services, "" being the server as a whole
+func newHealthChecker(db *sql.DB, logger *zap.Logger, interval time.Duration, timeout time.Duration) *healthChecker {
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+
+	return &healthChecker{
+		server:   health.NewServer(),
+		db:       db,
+		services: []string{""},
+		checks:   make(map[string]func(ctx context.Context) error),
+		failing:  make(map[string]bool),
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+		stop:     make(chan struct{}),
+	}
+}
+
+// watch - Add services whose status follows the database
+func (h *healthChecker) watch(services ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.services = append(h.services, services...)
+}
+
+// watchCheck - Add services whose status follows the database and the check
+func (h *healthChecker) watchCheck(check func(ctx context.Context) error, services ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.services = append(h.services, services...)
+	for _, s := range services {
+		h.checks[s] = check
+	}
+}
+
+// start - Probe the database now and then periodically until shutdown
+func (h *healthChecker) start() {
+	h.probe()
+
+	go func() {
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-h.stop:
+				return
+			case <-ticker.C:
+				h.probe()
+			}
+		}
+	}()
+}
+
+// probe - Ping the database and update the status of every service
+func (h *healthChecker) probe() {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	err := h.db.PingContext(ctx)
+
+	h.mu.Lock()
+	checks := make(map[string]func(ctx context.Context) error, len(h.checks))
+	for s, check := range h.checks {
+		checks[s] = check
+	}
+	h.mu.Unlock()
+
+	// the checks run without the lock, they may take up to the timeout
+	failed := make(map[string]error)
+	for s, check := range checks {
+		if e := check(ctx); e != nil {
+			failed[s] = e
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.stopped {
+		return
+	}
+
+	for s := range checks {
+		switch e := failed[s]; {
+		case e != nil && !h.failing[s]:
+			h.logger.Error("Service health check failed, reporting NOT_SERVING", zap.String("service", s), zap.Error(e))
+		case e == nil && h.failing[s]:
+			h.logger.Info("Service health check passed", zap.String("service", s))
+		}
+		h.failing[s] = failed[s] != nil
+	}
+
+	// only log the transitions
+	switch {
+	case err != nil && (h.serving || !h.probed):
+		h.logger.Error("Database unreachable, reporting NOT_SERVING", zap.Error(err))
+	case err == nil && !h.serving:
+		h.logger.Info("Database reachable, reporting SERVING")
+	}
+
+	h.probed = true
+	h.serving = err == nil
+	for _, s := range h.services {
+		h.server.SetServingStatus(s, h.status(s))
+	}
+}
+
+// shutdown - Report NOT_SERVING for good and stop probing, called when the server starts draining
+func (h *healthChecker) shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.stopped {
+		return
+	}
+
+	h.stopped = true
+	h.set(healthpb.HealthCheckResponse_NOT_SERVING)
+	close(h.stop)
+}
+
+// status - Status of the service matching the last probe, the caller holds the lock
+func (h *healthChecker) status(service string) healthpb.HealthCheckResponse_ServingStatus {
+	if h.serving && !h.failing[service] {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+// set - Report the status for every service, the caller holds the lock
+func (h *healthChecker) set(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, s := range h.services {
+		h.server.SetServingStatus(s, status)
+	}
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const todoService = "todo.ToDoService"
+
+func Test_healthChecker(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	h := newHealthChecker(db, zap.NewNop(), time.Hour, time.Second)
+	h.watch(todoService)
+
+	h.probe()
+	assertStatus(t, h, todoService, healthpb.HealthCheckResponse_SERVING)
+	assertStatus(t, h, "", healthpb.HealthCheckResponse_SERVING)
+
+	// a closed pool fails the ping like an unreachable server
+	db.Close()
+	h.probe()
+	assertStatus(t, h, todoService, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	h.shutdown()
+	h.shutdown()
+	assertStatus(t, h, "", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func Test_healthChecker_recovers(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	h := newHealthChecker(db, zap.NewNop(), time.Hour, time.Second)
+	h.watch(todoService)
+
+	h.serving, h.probed = false, true
+	h.set(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	h.probe()
+	assertStatus(t, h, todoService, healthpb.HealthCheckResponse_SERVING)
+
+	// nothing flips the status back once draining started
+	h.shutdown()
+	h.probe()
+	assertStatus(t, h, todoService, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func assertStatus(t *testing.T, h *healthChecker, service string, want healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+
+	res, err := h.server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q) error = %v", service, err)
+	}
+	if res.Status != want {
+		t.Errorf("Check(%q) = %v, want %v", service, res.Status, want)
+	}
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"grpoc/modules"
+	"grpoc/modules/trace"
+)
+
+const (
+	ConfigKeyInterceptors = modules.ConfigKeyInterceptors
+	ConfigKeyTimingSlow   = modules.ConfigKeyTimingSlow
+
+	// Built in interceptors
+	InterceptorRequestID = modules.InterceptorRequestID
+	InterceptorLogging   = modules.InterceptorLogging
+	InterceptorRecovery  = modules.InterceptorRecovery
+	InterceptorTiming    = modules.InterceptorTiming
+	InterceptorAuth      = modules.InterceptorAuth
+	InterceptorAuthz     = modules.InterceptorAuthz
+	InterceptorMetrics   = modules.InterceptorMetrics
+	InterceptorTracing   = modules.InterceptorTracing
+)
+
+// Interceptor - Unary and stream interceptor pair applied together, either may be nil
+type Interceptor struct {
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// builtinInterceptors - Factories of the interceptors that ship with the app, by config name
+var builtinInterceptors = map[string]func(app *App) (Interceptor, error){
+	InterceptorRequestID: func(app *App) (Interceptor, error) {
+		return Interceptor{
+			Unary:  modules.UnaryRequestIDInterceptor(),
+			Stream: modules.StreamRequestIDInterceptor(),
+		}, nil
+	},
+	InterceptorLogging: func(app *App) (Interceptor, error) {
+		return Interceptor{
+			Unary:  modules.UnaryLoggerInterceptor(app.logger),
+			Stream: modules.StreamLoggerInterceptor(app.logger),
+		}, nil
+	},
+	InterceptorRecovery: func(app *App) (Interceptor, error) {
+		return Interceptor{
+			Unary:  modules.UnaryRecoveryInterceptor(app.logger),
+			Stream: modules.StreamRecoveryInterceptor(app.logger),
+		}, nil
+	},
+	InterceptorTiming: func(app *App) (Interceptor, error) {
+		slow := app.container.Get(modules.InstConfig).(*modules.AppConfig).Timing.Slow
+		return Interceptor{
+			Unary:  modules.UnaryTimingInterceptor(app.logger, slow),
+			Stream: modules.StreamTimingInterceptor(app.logger, slow),
+		}, nil
+	},
+	InterceptorAuth: func(app *App) (Interceptor, error) {
+		auth, err := app.container.SafeGet(modules.InstAuth)
+		if err != nil {
+			return Interceptor{}, err
+		}
+		return Interceptor{
+			Unary:  auth.(*modules.Authenticator).UnaryInterceptor(),
+			Stream: auth.(*modules.Authenticator).StreamInterceptor(),
+		}, nil
+	},
+	InterceptorMetrics: func(app *App) (Interceptor, error) {
+		metrics, err := app.container.SafeGet(modules.InstMetrics)
+		if err != nil {
+			return Interceptor{}, err
+		}
+		return Interceptor{
+			Unary:  metrics.(*modules.Metrics).UnaryInterceptor(),
+			Stream: metrics.(*modules.Metrics).StreamInterceptor(),
+		}, nil
+	},
+	InterceptorTracing: func(app *App) (Interceptor, error) {
+		tracer, err := app.container.SafeGet(modules.InstTracer)
+		if err != nil {
+			return Interceptor{}, err
+		}
+		return Interceptor{
+			Unary:  trace.UnaryServerInterceptor(tracer.(*trace.Tracer)),
+			Stream: trace.StreamServerInterceptor(tracer.(*trace.Tracer)),
+		}, nil
+	},
+	InterceptorAuthz: func(app *App) (Interceptor, error) {
+		authz, err := app.container.SafeGet(modules.InstAuthz)
+		if err != nil {
+			return Interceptor{}, err
+		}
+		return Interceptor{
+			Unary:  authz.(*modules.Authorizer).UnaryInterceptor(),
+			Stream: authz.(*modules.Authorizer).StreamInterceptor(),
+		}, nil
+	},
+}
+
+// UseUnary - Register unary interceptors, they run in the order of registration after the built in ones.
+// It must be called before Run
+func (app *App) UseUnary(interceptors ...grpc.UnaryServerInterceptor) {
+	for _, i := range interceptors {
+		app.interceptors = append(app.interceptors, Interceptor{Unary: i})
+	}
+}
+
+// UseStream - Register stream interceptors, they run in the order of registration after the built in ones.
+// It must be called before Run
+func (app *App) UseStream(interceptors ...grpc.StreamServerInterceptor) {
+	for _, i := range interceptors {
+		app.interceptors = append(app.interceptors, Interceptor{Stream: i})
+	}
+}
+
+// serverInterceptors - Server options installing the built in interceptors enabled in the config followed by the registered ones
+func (app *App) serverInterceptors() (opts []grpc.ServerOption, err error) {
+	var (
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
+	)
+
+	names := app.container.Get(modules.InstConfig).(*modules.AppConfig).InterceptorChain()
+
+	interceptors := make([]Interceptor, 0, len(names)+len(app.interceptors))
+	for _, name := range names {
+		build, ok := builtinInterceptors[name]
+		if !ok {
+			err = fmt.Errorf("unknown interceptor '%s' in %s", name, ConfigKeyInterceptors)
+			return
+		}
+		var i Interceptor
+		if i, err = build(app); err != nil {
+			return
+		}
+		interceptors = append(interceptors, i)
+	}
+	interceptors = append(interceptors, app.interceptors...)
+
+	for _, i := range interceptors {
+		if i.Unary != nil {
+			unary = append(unary, i.Unary)
+		}
+		if i.Stream != nil {
+			stream = append(stream, i.Stream)
+		}
+	}
+
+	opts = append(opts, grpc.UnaryInterceptor(chainUnary(unary)), grpc.StreamInterceptor(chainStream(stream)))
+
+	return
+}
+
+// chainUnary - Combine the unary interceptors into one, the first one is the outermost
+func chainUnary(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+
+		return chained(ctx, req)
+	}
+}
+
+// chainStream - Combine the stream interceptors into one, the first one is the outermost
+func chainStream(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+
+		return chained(srv, ss)
+	}
+}
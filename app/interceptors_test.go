@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func Test_chainUnary(t *testing.T) {
+	var calls []string
+
+	record := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name+" in")
+			resp, err := handler(ctx, req)
+			calls = append(calls, name+" out")
+			return resp, err
+		}
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return req, nil
+	}
+
+	chained := chainUnary([]grpc.UnaryServerInterceptor{record("first"), record("second")})
+	resp, err := chained(context.Background(), "req", &grpc.UnaryServerInfo{FullMethod: "/todo.ToDoService/Read"}, handler)
+	if err != nil || resp != "req" {
+		t.Fatalf("chainUnary() = %v, %v, want req, nil", resp, err)
+	}
+
+	want := []string{"first in", "second in", "handler", "second out", "first out"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("chainUnary() calls = %v, want %v", calls, want)
+	}
+}
+
+func Test_chainStream(t *testing.T) {
+	var calls []string
+
+	record := func(name string) grpc.StreamServerInterceptor {
+		return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			calls = append(calls, name)
+			return handler(srv, ss)
+		}
+	}
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	}
+
+	chained := chainStream([]grpc.StreamServerInterceptor{record("first"), record("second")})
+	if err := chained(nil, nil, &grpc.StreamServerInfo{}, handler); err != nil {
+		t.Fatalf("chainStream() error = %v", err)
+	}
+
+	want := []string{"first", "second", "handler"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("chainStream() calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"net"
+
+	cont "github.com/sarulabs/di"
+	"google.golang.org/grpc"
+	"grpoc/modules"
+)
+
+// Option - Configures the application, passed to NewApp
+type Option func(app *App)
+
+// WithConfigSource - Read the config from the source, with its overrides and defaults
+func WithConfigSource(source modules.ConfigSource) Option {
+	return func(app *App) {
+		app.configSource = source
+	}
+}
+
+// WithContainer - Use a container built by the caller instead of the one of the config source.
+// It needs the definitions of modules.InitContainer, and the caller deletes it once Run returned.
+// Without modules.InitContext the database ping of the start can not be cancelled
+func WithContainer(container cont.Container) Option {
+	return func(app *App) {
+		app.container = container
+		app.ownsContainer = false
+	}
+}
+
+// WithListener - Serve on the listener, e.g. a bufconn in tests, instead of the port of the config
+func WithListener(listener net.Listener) Option {
+	return func(app *App) {
+		app.listener = listener
+	}
+}
+
+// WithServerOptions - Add options to the grpc server, after the ones of the app. The app installs the
+// interceptor chain itself, grpc takes one grpc.UnaryInterceptor and one grpc.StreamInterceptor only, so
+// Run fails when the options set one. Add interceptors with WithUnaryInterceptors and WithStreamInterceptors
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(app *App) {
+		app.serverOptions = append(app.serverOptions, opts...)
+	}
+}
+
+// WithUnaryInterceptors - Add unary interceptors to the chain, they run in order after the built in ones
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(app *App) {
+		app.UseUnary(interceptors...)
+	}
+}
+
+// WithStreamInterceptors - Add stream interceptors to the chain, they run in order after the built in ones
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(app *App) {
+		app.UseStream(interceptors...)
+	}
+}
+
+// WithRegistry - Register the services of the registry instead of the ones of DefaultRegistry
+func WithRegistry(registry *Registry) Option {
+	return func(app *App) {
+		app.registry = registry
+	}
+}
+
+// WithServices - Register more services on the grpc server, after the ones of the registry
+func WithServices(services ...Service) Option {
+	return func(app *App) {
+		app.services = append(app.services, services...)
+	}
+}
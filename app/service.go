@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	cont "github.com/sarulabs/di"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"grpoc/modules"
+)
+
+// Service - A grpc service the app registers, app.services.<name>.enabled: false in the config switches it off.
+// It can implement ServiceInitializer, ServiceCloser and ServiceHealthChecker for its lifecycle
+type Service interface {
+	// Name of the service in the config, lower case
+	Name() string
+	// Register the implementation on the server, the resources come from the container
+	Register(server *grpc.Server, container cont.Container)
+}
+
+// ServiceInitializer - Service preparing its resources before it is registered, an error stops the start
+type ServiceInitializer interface {
+	Init(container cont.Container) error
+}
+
+// ServiceCloser - Service releasing its resources once the server stopped
+type ServiceCloser interface {
+	Close() error
+}
+
+// ServiceHealthChecker - Service with a health of its own, it is NOT_SERVING while the check fails
+// or the database is unreachable
+type ServiceHealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// serviceFunc - Service of a registration function
+type serviceFunc struct {
+	name     string
+	register func(server *grpc.Server, container cont.Container)
+}
+
+func (s serviceFunc) Name() string {
+	return s.name
+}
+
+func (s serviceFunc) Register(server *grpc.Server, container cont.Container) {
+	s.register(server, container)
+}
+
+// NewService - Service registered by a function, for the services without lifecycle hooks
+func NewService(name string, register func(server *grpc.Server, container cont.Container)) Service {
+	return serviceFunc{name: name, register: register}
+}
+
+// Registry - Services in the order they were added, their names are unique
+type Registry struct {
+	mu       sync.Mutex
+	services []Service
+}
+
+// NewRegistry - Registry of the services, it fails on a duplicate name
+func NewRegistry(services ...Service) (*Registry, error) {
+	r := &Registry{}
+
+	for _, s := range services {
+		if err := r.Add(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Add - Add the service, its name must not be taken
+func (r *Registry) Add(s Service) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, registered := range r.services {
+		if strings.EqualFold(registered.Name(), s.Name()) {
+			return fmt.Errorf("service %s is already registered", s.Name())
+		}
+	}
+
+	r.services = append(r.services, s)
+
+	return nil
+}
+
+// Services - The services in the order they were added
+func (r *Registry) Services() []Service {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Service(nil), r.services...)
+}
+
+// DefaultRegistry - Services of the apps not given a registry with WithRegistry. The service packages add
+// themselves from init, the program imports them, blank when it does not use them otherwise
+var DefaultRegistry = &Registry{}
+
+// RegisterService - Add a service to DefaultRegistry, usually from the init function of its package.
+// It panics when the name is taken, like sql.Register
+func RegisterService(s Service) {
+	if err := DefaultRegistry.Add(s); err != nil {
+		panic(err)
+	}
+}
+
+// startServices - Initialize and register the enabled services of the registry followed by the ones of WithServices.
+// The health of the grpc services they register follows the database and their own check
+func (app *App) startServices(cfg *modules.AppConfig) (err error) {
+	services := app.registry.Services()
+
+	known := make(map[string]bool, len(services)+len(app.services))
+	for _, s := range services {
+		known[strings.ToLower(s.Name())] = true
+	}
+	for _, s := range app.services {
+		if known[strings.ToLower(s.Name())] {
+			return fmt.Errorf("service %s is already registered", s.Name())
+		}
+		known[strings.ToLower(s.Name())] = true
+		services = append(services, s)
+	}
+
+	for name := range cfg.Services {
+		if !known[name] {
+			return fmt.Errorf("unknown service '%s' in %s", name, modules.ConfigKeyServices)
+		}
+	}
+
+	for _, s := range services {
+		if !cfg.ServiceEnabled(s.Name()) {
+			app.logger.Info("Service disabled", zap.String("service", s.Name()))
+			continue
+		}
+
+		if i, ok := s.(ServiceInitializer); ok {
+			if err = i.Init(app.container); err != nil {
+				return fmt.Errorf("failed to initialize service %s-> %v", s.Name(), err)
+			}
+		}
+		app.running = append(app.running, s)
+
+		registered := app.server.GetServiceInfo()
+		s.Register(app.server, app.container)
+
+		var names []string
+		for name := range app.server.GetServiceInfo() {
+			if _, ok := registered[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		if h, ok := s.(ServiceHealthChecker); ok {
+			app.health.watchCheck(h.CheckHealth, names...)
+		} else {
+			app.health.watch(names...)
+		}
+
+		app.logger.Info("Registered service", zap.String("service", s.Name()), zap.Strings("grpc", names))
+	}
+
+	return
+}
+
+// closeServices - Close the started services, the last started first
+func (app *App) closeServices() {
+	for i := len(app.running) - 1; i >= 0; i-- {
+		if c, ok := app.running[i].(ServiceCloser); ok {
+			if err := c.Close(); err != nil {
+				app.logger.Error("Failed to close service", zap.String("service", app.running[i].Name()), zap.Error(err))
+			}
+		}
+	}
+
+	app.running = nil
+}
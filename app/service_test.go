@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	cont "github.com/sarulabs/di"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"grpoc/modules"
+)
+
+// pluginService - Service with every lifecycle hook
+type pluginService struct {
+	health error
+	inits  int
+	closes int
+}
+
+func (s *pluginService) Name() string {
+	return "plugin"
+}
+
+func (s *pluginService) Register(server *grpc.Server, container cont.Container) {
+	testpb.RegisterTestServiceServer(server, &testService{})
+}
+
+func (s *pluginService) Init(container cont.Container) error {
+	s.inits++
+	return nil
+}
+
+func (s *pluginService) Close() error {
+	s.closes++
+	return nil
+}
+
+func (s *pluginService) CheckHealth(ctx context.Context) error {
+	return s.health
+}
+
+// serviceApp - App ready to start the services, with the config overrides
+func serviceApp(t *testing.T, overrides modules.ConfigOverrides, opts ...Option) (*App, *modules.AppConfig) {
+	t.Helper()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := NewApp(opts...)
+	app.container = testContainer(t, overrides)
+	app.logger = zap.NewNop()
+	app.server = grpc.NewServer()
+	app.health = newHealthChecker(db, app.logger, time.Hour, time.Second)
+
+	return app, app.container.Get(modules.InstConfig).(*modules.AppConfig)
+}
+
+func TestApp_startServices(t *testing.T) {
+	plugin := &pluginService{health: errors.New("queue unreachable")}
+	registry, err := NewRegistry(plugin)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	disabled := NewService("disabled", func(server *grpc.Server, container cont.Container) {
+		t.Error("Register() of a disabled service called")
+	})
+
+	app, cfg := serviceApp(t, modules.ConfigOverrides{"app.services.disabled.enabled": "false"}, WithRegistry(registry), WithServices(disabled))
+	defer app.container.Delete()
+
+	if err = app.startServices(cfg); err != nil {
+		t.Fatalf("startServices() error = %v", err)
+	}
+	if plugin.inits != 1 {
+		t.Errorf("Init() called %d times, want 1", plugin.inits)
+	}
+
+	app.health.probe()
+	assertStatus(t, app.health, "grpc.testing.TestService", healthpb.HealthCheckResponse_NOT_SERVING)
+	assertStatus(t, app.health, "", healthpb.HealthCheckResponse_SERVING)
+
+	plugin.health = nil
+	app.health.probe()
+	assertStatus(t, app.health, "grpc.testing.TestService", healthpb.HealthCheckResponse_SERVING)
+
+	app.closeServices()
+	if plugin.closes != 1 {
+		t.Errorf("Close() called %d times, want 1", plugin.closes)
+	}
+}
+
+func TestApp_startServices_invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides modules.ConfigOverrides
+		services  []Service
+		wantErr   string
+	}{
+		{
+			name:      "Unknown service in the config",
+			overrides: modules.ConfigOverrides{"app.services.nope.enabled": "true"},
+			wantErr:   "unknown service 'nope'",
+		},
+		{
+			name:     "Duplicate of a registry service",
+			services: []Service{NewService("Plugin", func(*grpc.Server, cont.Container) {})},
+			wantErr:  "service Plugin is already registered",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := NewRegistry(&pluginService{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			app, cfg := serviceApp(t, tt.overrides, WithRegistry(registry), WithServices(tt.services...))
+			defer app.container.Delete()
+
+			err = app.startServices(cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("startServices() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	register := func(*grpc.Server, cont.Container) {}
+
+	if _, err := NewRegistry(NewService("a", register), NewService("A", register)); err == nil {
+		t.Error("NewRegistry() error = nil, want an error for the duplicate name")
+	}
+}
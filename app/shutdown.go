@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"grpoc/modules"
+)
+
+const (
+	ConfigKeyShutdownTimeout = modules.ConfigKeyShutdownTimeout
+
+	DefaultShutdownTimeout = modules.DefaultShutdownTimeout
+)
+
+// drain - Report NOT_SERVING so the load balancers stop sending calls, then let the calls in flight finish.
+// The server is stopped, cancelling the remaining calls, once the timeout passes
+func (app *App) drain(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+
+	if app.health != nil {
+		app.health.shutdown()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		app.server.GracefulStop()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		app.logger.Info("Calls drained")
+	case <-time.After(timeout):
+		app.logger.Warn("Calls still running after the shutdown timeout, stopping", zap.Duration("timeout", timeout))
+		app.server.Stop()
+		<-drained
+	case <-app.stop:
+		app.logger.Info("Stopping grpc server")
+		app.server.Stop()
+		<-drained
+	}
+}
+
+// Shutdown - Stop the server like SIGTERM does and wait for Run to return.
+// When ctx ends first the server is stopped at once and the error of ctx is returned
+func (app *App) Shutdown(ctx context.Context) error {
+	app.shutdownOnce.Do(func() {
+		close(app.shutdown)
+	})
+
+	select {
+	case <-app.done:
+		return nil
+	case <-ctx.Done():
+		app.Stop()
+		return ctx.Err()
+	}
+}
+
+// Stop - Stop the server at once, the calls in flight are cancelled. Run returns once the resources are released
+func (app *App) Stop() {
+	app.stopOnce.Do(func() {
+		close(app.stop)
+	})
+}
+
+// close - Release the resources of the container, the database connections, watchers and exporters are closed
+func (app *App) close() {
+	if app.health != nil {
+		app.health.shutdown()
+	}
+
+	app.closeServices()
+
+	// a container of the caller is deleted by the caller
+	if !app.ownsContainer {
+		return
+	}
+
+	if app.logger != nil {
+		app.logger.Info("Releasing resources")
+	}
+
+	if err := app.container.Delete(); err != nil && app.logger != nil {
+		app.logger.Error("Failed to release resources", zap.Error(err))
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serveHealth - App serving the health service over an in memory listener, and a client connection to it
+func serveHealth(t *testing.T) (*App, *observer.ObservedLogs, *grpc.ClientConn) {
+	t.Helper()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	core, logs := observer.New(zap.InfoLevel)
+	app := &App{server: grpc.NewServer(), logger: zap.New(core)}
+	app.health = newHealthChecker(db, app.logger, time.Hour, time.Second)
+	healthpb.RegisterHealthServer(app.server, app.health.server)
+
+	listen := bufconn.Listen(1024 * 1024)
+	go app.server.Serve(listen)
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+		return listen.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app, logs, conn
+}
+
+func TestApp_drain(t *testing.T) {
+	app, logs, conn := serveHealth(t)
+	defer conn.Close()
+
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	app.drain(time.Second)
+
+	if logs.FilterMessage("Calls drained").Len() != 1 {
+		t.Errorf("drain() logs = %v, want the calls drained", logs.All())
+	}
+	if status, _ := app.health.server.Check(context.Background(), &healthpb.HealthCheckRequest{}); status.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("drain() health = %v, want NOT_SERVING", status.Status)
+	}
+}
+
+func TestApp_drain_timeout(t *testing.T) {
+	app, logs, conn := serveHealth(t)
+	defer conn.Close()
+
+	// a watch stays open until the client leaves, it outlives the shutdown timeout
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+
+	start := time.Now()
+	app.drain(50 * time.Millisecond)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("drain() took %s, want a forced stop after the timeout", elapsed)
+	}
+	if logs.FilterMessage("Calls still running after the shutdown timeout, stopping").Len() != 1 {
+		t.Errorf("drain() logs = %v, want the forced stop", logs.All())
+	}
+
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+}
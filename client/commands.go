@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"grpoc/services/todo"
+)
+
+// create a todo
+func create(e *env, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	title := fs.String("title", "", "title of the todo")
+	description := fs.String("description", "", "description of the todo")
+	reminder := fs.String("reminder", "", "reminder time in RFC3339, now when empty")
+	_ = fs.Parse(args)
+
+	rem, err := parseTime(*reminder, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := e.call()
+	defer cancel()
+
+	res, err := e.client.Create(ctx, &todo.CreateRequest{
+		Api: e.api,
+		ToDo: &todo.ToDo{
+			Title:       *title,
+			Description: *description,
+			Reminder:    rem,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return e.out.created(res)
+}
+
+// read a todo by id
+func read(e *env, args []string) error {
+	fs := flag.NewFlagSet("read", flag.ExitOnError)
+	id := fs.Int64("id", 0, "id of the todo")
+	_ = fs.Parse(args)
+
+	ctx, cancel := e.call()
+	defer cancel()
+
+	res, err := e.client.Read(ctx, &todo.ReadRequest{Api: e.api, Id: *id})
+	if err != nil {
+		return err
+	}
+
+	return e.out.todos(res, []*todo.ToDo{res.ToDo}, "")
+}
+
+// update the given fields of a todo, the others keep their value
+func update(e *env, args []string) error {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	id := fs.Int64("id", 0, "id of the todo")
+	title := fs.String("title", "", "new title")
+	description := fs.String("description", "", "new description")
+	reminder := fs.String("reminder", "", "new reminder time in RFC3339")
+	_ = fs.Parse(args)
+
+	ctx, cancel := e.call()
+	current, err := e.client.Read(ctx, &todo.ReadRequest{Api: e.api, Id: *id})
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	td := current.ToDo
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			td.Title = *title
+		case "description":
+			td.Description = *description
+		case "reminder":
+			td.Reminder, err = parseTime(*reminder, time.Time{})
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel = e.call()
+	defer cancel()
+
+	res, err := e.client.Update(ctx, &todo.UpdateRequest{Api: e.api, ToDo: td})
+	if err != nil {
+		return err
+	}
+
+	return e.out.affected(res, "updated", res.Updated)
+}
+
+// remove a todo by id
+func remove(e *env, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	id := fs.Int64("id", 0, "id of the todo")
+	_ = fs.Parse(args)
+
+	ctx, cancel := e.call()
+	defer cancel()
+
+	res, err := e.client.Delete(ctx, &todo.DeleteRequest{Api: e.api, Id: *id})
+	if err != nil {
+		return err
+	}
+
+	return e.out.affected(res, "deleted", res.Deleted)
+}
+
+// list todos page by page
+func list(e *env, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	size := fs.Int("page-size", 0, "todos per page, the server maximum when 0")
+	token := fs.String("page-token", "", "token of the page to list, from a previous list")
+	title := fs.String("title", "", "only todos whose title contains this text")
+	before := fs.String("before", "", "only todos with a reminder before this RFC3339 time")
+	after := fs.String("after", "", "only todos with a reminder after this RFC3339 time")
+	all := fs.Bool("all", false, "follow the page tokens and list every page")
+	_ = fs.Parse(args)
+
+	req := &todo.ListToDosRequest{
+		Api:           e.api,
+		PageSize:      int32(*size),
+		PageToken:     *token,
+		TitleContains: *title,
+	}
+
+	var err error
+	if req.ReminderBefore, err = parseOptionalTime(*before); err != nil {
+		return err
+	}
+	if req.ReminderAfter, err = parseOptionalTime(*after); err != nil {
+		return err
+	}
+
+	// every page gets the whole timeout, a long listing does not run out of time half way
+	for {
+		ctx, cancel := e.call()
+		res, err := e.client.ListToDos(ctx, req)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		if err = e.out.todos(res, res.ToDos, res.NextPageToken); err != nil {
+			return err
+		}
+
+		if !*all || res.NextPageToken == "" {
+			return nil
+		}
+		req.PageToken = res.NextPageToken
+	}
+}
+
+// parseTime parses an RFC3339 time, an empty value gives the fallback
+func parseTime(value string, fallback time.Time) (*timestamp.Timestamp, error) {
+	t := fallback
+	if value != "" {
+		var err error
+		if t, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, errors.New("time should be in RFC3339 format, e.g. 2019-08-01T15:04:05Z")
+		}
+	}
+
+	return ptypes.TimestampProto(t)
+}
+
+// parseOptionalTime parses an RFC3339 time, an empty value gives nil
+func parseOptionalTime(value string) (*timestamp.Timestamp, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	return parseTime(value, time.Time{})
+}
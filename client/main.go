@@ -2,54 +2,195 @@ package main
 
 import (
 	"context"
-	"log"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"time"
 
-	"github.com/golang/protobuf/ptypes"
 	"google.golang.org/grpc"
-	mymodel "grpoc/modules/model"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"grpoc/services/todo"
 )
 
+const usage = `Usage: client [flags] <command> [command flags]
+
+Commands:
+  create   create a todo
+  read     read a todo by id
+  update   update the given fields of a todo
+  delete   delete a todo by id
+  list     list todos page by page
+
+Run 'client <command> -h' for the flags of a command.
+
+Flags:
+`
+
+// options are the flags shared by every command
+type options struct {
+	addr       string
+	api        string
+	tls        bool
+	ca         string
+	cert       string
+	key        string
+	serverName string
+	timeout    time.Duration
+	output     string
+	token      string
+	apiKey     string
+}
+
+// env is what a command runs with
+type env struct {
+	// ctx carries the credentials, every call gets its own deadline from call
+	ctx     context.Context
+	timeout time.Duration
+	client  todo.ToDoServiceClient
+	out     printer
+	api     string
+}
+
+// command runs one subcommand against the service
+type command func(e *env, args []string) error
+
+var commands = map[string]command{
+	"create": create,
+	"read":   read,
+	"update": update,
+	"delete": remove,
+	"list":   list,
+}
+
 func main() {
-	conn, err := grpc.Dial("localhost:3000", grpc.WithInsecure())
+	opts, args, err := parseOptions(os.Args[1:])
+	if err == flag.ErrHelp {
+		os.Exit(0)
+	}
 	if err != nil {
-		log.Fatal(err)
+		os.Exit(2)
 	}
-	defer conn.Close()
 
-	//call to ToDo
-	c := todo.NewToDoServiceClient(conn)
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	if err = run(opts, args); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
 
-	t := time.Now().In(time.UTC)
-	reminder, _ := ptypes.TimestampProto(t)
-	pfx := t.Format(mymodel.SQLDatetime)
+// parseOptions parses the flags shared by every command, the remaining args are the command and its flags
+func parseOptions(args []string) (opts options, rest []string, err error) {
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	fs.StringVar(&opts.addr, "addr", "localhost:3000", "server address")
+	fs.StringVar(&opts.api, "api", "v1", "API version")
+	fs.BoolVar(&opts.tls, "tls", false, "connect with TLS")
+	fs.StringVar(&opts.ca, "ca", "", "CA bundle to verify the server with, system roots when empty")
+	fs.StringVar(&opts.cert, "cert", "", "client certificate for mutual TLS")
+	fs.StringVar(&opts.key, "key", "", "client certificate key for mutual TLS")
+	fs.StringVar(&opts.serverName, "server-name", "", "name to verify the server certificate against, the host of -addr when empty")
+	fs.DurationVar(&opts.timeout, "timeout", 10*time.Second, "deadline of every call, each page of list -all gets its own")
+	fs.StringVar(&opts.output, "o", outputTable, "output format: table or json")
+	fs.StringVar(&opts.token, "token", os.Getenv("GRPOC_TOKEN"), "bearer JWT to authenticate with, $GRPOC_TOKEN by default")
+	fs.StringVar(&opts.apiKey, "api-key", os.Getenv("GRPOC_API_KEY"), "API key to authenticate with, $GRPOC_API_KEY by default")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	if err = fs.Parse(args); err != nil {
+		return
+	}
 
-	reqCreate := todo.CreateRequest{
-		Api: "v1",
-		ToDo: &todo.ToDo{
-			Title:       "Title" + pfx,
-			Description: "description" + pfx,
-			Reminder:    reminder,
-		},
+	return opts, fs.Args(), nil
+}
+
+// run dials the server and runs the command
+func run(opts options, args []string) error {
+	cmd, err := lookup(args)
+	if err != nil {
+		return err
 	}
 
-	resCreate, err := c.Create(ctx, &reqCreate)
+	p, err := newPrinter(opts.output, os.Stdout)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	log.Println("Response:", resCreate)
 
-	reqAdd := todo.ReadRequest{
-		Api: "v1",
-		Id:  2,
+	dialOpts, err := dialOptions(opts)
+	if err != nil {
+		return err
 	}
 
-	resRead, err := c.Read(ctx, &reqAdd)
+	conn, err := grpc.Dial(opts.addr, dialOpts...)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	log.Println("Response:", resRead)
+	defer conn.Close()
+
+	return cmd(newEnv(opts, todo.NewToDoServiceClient(conn), p), args[1:])
+}
+
+// lookup returns the command named by the first arg
+func lookup(args []string) (command, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no command given, run 'client -h' for the usage")
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown command '%s', run 'client -h' for the usage", args[0])
+	}
+
+	return cmd, nil
+}
+
+// newEnv returns the env of the commands, the calls carry the credentials of the options
+func newEnv(opts options, client todo.ToDoServiceClient, out printer) *env {
+	ctx := context.Background()
+	if opts.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+opts.token)
+	}
+	if opts.apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", opts.apiKey)
+	}
+
+	return &env{ctx: ctx, timeout: opts.timeout, client: client, out: out, api: opts.api}
+}
+
+// call returns the context of one call, it ends after the timeout of the options
+func (e *env) call() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(e.ctx, e.timeout)
+}
+
+// dialOptions returns the transport credentials matching the options
+func dialOptions(opts options) ([]grpc.DialOption, error) {
+	if !opts.tls {
+		return []grpc.DialOption{grpc.WithInsecure()}, nil
+	}
+
+	config := &tls.Config{ServerName: opts.serverName}
+	if opts.ca != "" {
+		pem, err := ioutil.ReadFile(opts.ca)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", opts.ca)
+		}
+	}
+
+	if opts.cert != "" || opts.key != "" {
+		cert, err := tls.LoadX509KeyPair(opts.cert, opts.key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate-> %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(config))}, nil
 }
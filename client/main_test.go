@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"grpoc/services/todo"
+)
+
+// fakeServer serves pages of one todo each, a page takes delay to answer
+type fakeServer struct {
+	todo.UnimplementedToDoServiceServer
+
+	pages  int
+	delay  time.Duration
+	lists  []*todo.ListToDosRequest
+	create *todo.CreateRequest
+	apiKey string
+}
+
+func (s *fakeServer) Create(ctx context.Context, req *todo.CreateRequest) (*todo.CreateResponse, error) {
+	s.create = req
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("x-api-key")) > 0 {
+		s.apiKey = md.Get("x-api-key")[0]
+	}
+
+	return &todo.CreateResponse{Api: req.Api, Id: 7}, nil
+}
+
+func (s *fakeServer) ListToDos(ctx context.Context, req *todo.ListToDosRequest) (*todo.ListToDosResponse, error) {
+	s.lists = append(s.lists, req)
+
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	page := len(s.lists)
+	res := &todo.ListToDosResponse{
+		Api:   req.Api,
+		ToDos: []*todo.ToDo{{Id: int64(page), Title: fmt.Sprintf("todo %d", page), Reminder: ptypes.TimestampNow()}},
+	}
+	if page < s.pages {
+		res.NextPageToken = fmt.Sprintf("page-%d", page+1)
+	}
+
+	return res, nil
+}
+
+// testEnv - Env of the commands calling the fake server in process
+func testEnv(t *testing.T, server *fakeServer, opts options, out printer) *env {
+	t.Helper()
+
+	listen := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	todo.RegisterToDoServiceServer(s, server)
+	go s.Serve(listen)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufconn", grpc.WithInsecure(), grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+		return listen.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return newEnv(opts, todo.NewToDoServiceClient(conn), out)
+}
+
+func TestParseOptions(t *testing.T) {
+	opts, rest, err := parseOptions([]string{"-addr", "todo:3000", "-o", "json", "-timeout", "3s", "list", "-all"})
+	if err != nil {
+		t.Fatalf("parseOptions() error = %v", err)
+	}
+	if opts.addr != "todo:3000" || opts.output != outputJSON || opts.timeout != 3*time.Second || opts.api != "v1" {
+		t.Errorf("parseOptions() = %+v", opts)
+	}
+	if strings.Join(rest, " ") != "list -all" {
+		t.Errorf("parseOptions() args = %v, want the command and its flags", rest)
+	}
+
+	if _, _, err = parseOptions([]string{"-timeout", "soon"}); err == nil {
+		t.Error("parseOptions() error = nil, want an error for the timeout")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "Command", args: []string{"list", "-all"}},
+		{name: "No command", wantErr: "no command given"},
+		{name: "Unknown command", args: []string{"purge"}, wantErr: "unknown command 'purge'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := lookup(tt.args)
+			if tt.wantErr == "" && (err != nil || cmd == nil) {
+				t.Errorf("lookup() = %v, want the command", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("lookup() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var (
+		out    bytes.Buffer
+		server = &fakeServer{}
+	)
+
+	e := testEnv(t, server, options{api: "v1", apiKey: "dev-key", timeout: time.Second}, &tablePrinter{w: &out})
+	if err := create(e, []string{"-title", "Write docs", "-description", "for the client", "-reminder", "2019-08-01T15:04:05Z"}); err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+
+	if server.create.ToDo.Title != "Write docs" || server.create.ToDo.Description != "for the client" || server.create.ToDo.Reminder.Seconds != 1564671845 {
+		t.Errorf("create() sent %+v", server.create.ToDo)
+	}
+	if server.apiKey != "dev-key" {
+		t.Errorf("create() sent the api key %q, want dev-key", server.apiKey)
+	}
+	if out.String() != "created todo 7\n" {
+		t.Errorf("create() printed %q", out.String())
+	}
+
+	if err := create(e, []string{"-reminder", "tomorrow"}); err == nil {
+		t.Error("create() error = nil, want an error for the reminder")
+	}
+}
+
+func TestList_all(t *testing.T) {
+	var out bytes.Buffer
+
+	// the listing takes longer than the timeout, every page is within it
+	server := &fakeServer{pages: 3, delay: 100 * time.Millisecond}
+	e := testEnv(t, server, options{api: "v1", timeout: 250 * time.Millisecond}, &jsonPrinter{w: &out})
+
+	if err := list(e, []string{"-all", "-page-size", "1", "-title", "todo"}); err != nil {
+		t.Fatalf("list() error = %v", err)
+	}
+
+	if len(server.lists) != 3 {
+		t.Fatalf("list() listed %d pages, want 3", len(server.lists))
+	}
+	for i, want := range []string{"", "page-2", "page-3"} {
+		if req := server.lists[i]; req.PageToken != want || req.PageSize != 1 || req.TitleContains != "todo" {
+			t.Errorf("list() page %d request = %+v, want the token %q", i+1, req, want)
+		}
+	}
+	if n := strings.Count(out.String(), `"title": "todo`); n != 3 {
+		t.Errorf("list() printed %d todos, want 3:\n%s", n, out.String())
+	}
+}
+
+func TestList_page(t *testing.T) {
+	var out bytes.Buffer
+
+	server := &fakeServer{pages: 3}
+	e := testEnv(t, server, options{api: "v1", timeout: time.Second}, &tablePrinter{w: &out})
+
+	if err := list(e, []string{"-page-token", "page-2"}); err != nil {
+		t.Fatalf("list() error = %v", err)
+	}
+
+	if len(server.lists) != 1 || server.lists[0].PageToken != "page-2" {
+		t.Errorf("list() requests = %v, want the given page only", server.lists)
+	}
+	if !strings.Contains(out.String(), "next page token: page-2\n") {
+		t.Errorf("list() printed %q, want the next page token", out.String())
+	}
+}
+
+func TestNewPrinter(t *testing.T) {
+	reminder, _ := ptypes.TimestampProto(time.Date(2019, 8, 1, 15, 4, 5, 0, time.UTC))
+	res := &todo.ListToDosResponse{
+		Api:           "v1",
+		ToDos:         []*todo.ToDo{{Id: 1, Title: "Write docs", Description: "client", Reminder: reminder}},
+		NextPageToken: "next",
+	}
+
+	tests := []struct {
+		format  string
+		want    []string
+		wantErr bool
+	}{
+		{format: outputTable, want: []string{"ID  TITLE       DESCRIPTION  REMINDER", "1   Write docs  client       2019-08-01T15:04:05Z", "next page token: next"}},
+		{format: outputJSON, want: []string{`"title": "Write docs"`, `"nextPageToken": "next"`}},
+		{format: "yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+
+			p, err := newPrinter(tt.format, &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newPrinter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if err = p.todos(res, res.ToDos, res.NextPageToken); err != nil {
+				t.Fatalf("todos() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("todos() printed\n%s\nwant %q", out.String(), want)
+				}
+			}
+
+			out.Reset()
+			if err = p.affected(&todo.DeleteResponse{Api: "v1", Deleted: 2}, "deleted", 2); err != nil {
+				t.Fatalf("affected() error = %v", err)
+			}
+			if tt.format == outputTable && out.String() != "deleted 2 todo(s)\n" {
+				t.Errorf("affected() printed %q", out.String())
+			}
+		})
+	}
+}
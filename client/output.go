@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"grpoc/services/todo"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printer writes the responses in one output format
+type printer interface {
+	created(res *todo.CreateResponse) error
+	todos(res proto.Message, todos []*todo.ToDo, next string) error
+	affected(res proto.Message, verb string, n int64) error
+}
+
+// newPrinter returns the printer of the output format
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case outputTable:
+		return &tablePrinter{w: w}, nil
+	case outputJSON:
+		return &jsonPrinter{w: w}, nil
+	}
+
+	return nil, fmt.Errorf("unknown output format '%s', should be %s or %s", format, outputTable, outputJSON)
+}
+
+// tablePrinter writes human readable tables
+type tablePrinter struct {
+	w io.Writer
+}
+
+func (p *tablePrinter) created(res *todo.CreateResponse) error {
+	_, err := fmt.Fprintf(p.w, "created todo %d\n", res.Id)
+	return err
+}
+
+func (p *tablePrinter) todos(res proto.Message, todos []*todo.ToDo, next string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tDESCRIPTION\tREMINDER")
+	for _, td := range todos {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", td.Id, td.Title, td.Description, formatTime(td))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if next != "" {
+		_, err := fmt.Fprintf(p.w, "next page token: %s\n", next)
+		return err
+	}
+
+	return nil
+}
+
+func (p *tablePrinter) affected(res proto.Message, verb string, n int64) error {
+	_, err := fmt.Fprintf(p.w, "%s %d todo(s)\n", verb, n)
+	return err
+}
+
+// jsonPrinter writes every response as a JSON document
+type jsonPrinter struct {
+	w io.Writer
+}
+
+func (p *jsonPrinter) created(res *todo.CreateResponse) error {
+	return p.print(res)
+}
+
+func (p *jsonPrinter) todos(res proto.Message, todos []*todo.ToDo, next string) error {
+	return p.print(res)
+}
+
+func (p *jsonPrinter) affected(res proto.Message, verb string, n int64) error {
+	return p.print(res)
+}
+
+func (p *jsonPrinter) print(res proto.Message) error {
+	m := jsonpb.Marshaler{Indent: "  "}
+	if err := m.Marshal(p.w, res); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(p.w)
+	return err
+}
+
+// formatTime formats the reminder of the todo in RFC3339
+func formatTime(td *todo.ToDo) string {
+	t, err := ptypes.Timestamp(td.Reminder)
+	if err != nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -1,15 +1,19 @@
 module grpoc
 
-go 1.12
+go 1.15
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.3.3
+	github.com/fsnotify/fsnotify v1.4.7
 	github.com/go-sql-driver/mysql v1.4.1
 	github.com/golang/protobuf v1.3.2
 	github.com/jmoiron/sqlx v1.2.0
+	github.com/mitchellh/mapstructure v1.1.2
+	github.com/prometheus/client_golang v1.1.0
 	github.com/sarulabs/di v2.0.0+incompatible
 	github.com/spf13/viper v1.4.0
 	github.com/stretchr/testify v1.3.0 // indirect
+	go.uber.org/zap v1.10.0
 	golang.org/x/net v0.0.0-20190724013045-ca1201d0de80 // indirect
 	golang.org/x/sys v0.0.0-20190801041406-cbf593c0f2f3 // indirect
 	golang.org/x/text v0.3.2 // indirect
@@ -2,19 +2,54 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
 	"os"
 
+	"go.uber.org/zap"
 	"grpoc/app"
+	"grpoc/modules"
+	// services registering themselves on app.DefaultRegistry
+	_ "grpoc/services/todo"
 )
 
+// shorthands - Flags of the most overridden config keys, -set covers every other key
+var shorthands = map[string]string{
+	"port":      app.ConfigKeyAppPort,
+	"log-level": modules.ConfigKeyLogLevel,
+	"db-host":   modules.ConfigKeyDbHost,
+	"db-port":   modules.ConfigKeyDbPort,
+	"db-user":   modules.ConfigKeyDbUser,
+	"db-name":   modules.ConfigKeyDbName,
+}
+
 func main() {
-	application := app.NewApp()
+	source := modules.ConfigSource{Overrides: modules.ConfigOverrides{}}
+
+	flag.StringVar(&source.Path, "config", "", "directory of config.yaml, defaults to $"+modules.ConfigPath+" or "+modules.DefaultConfigPath)
+	flag.Var(source.Overrides, "set", "override any config key as key=value, can be repeated. Lists take comma or space separated values")
+	for name, key := range shorthands {
+		flag.String(name, "", "overrides "+key)
+	}
+	flag.Parse()
+
+	// only the flags given on the command line override, an empty default is not a setting
+	flag.Visit(func(f *flag.Flag) {
+		if key, ok := shorthands[f.Name]; ok {
+			source.Overrides[key] = f.Value.String()
+		}
+	})
+
+	application := app.NewApp(app.WithConfigSource(source))
 
 	ctx := context.Background()
 
 	if err := application.Run(ctx); err != nil {
-		log.Println(err)
+		// the logger of the config may not exist, e.g. when the config is invalid. The error is
+		// written as a JSON line to stderr like the logs of the app
+		if logger, e := modules.NewLogger("", modules.LogEncodingJSON); e == nil {
+			logger.Error("Failed to run", zap.Error(err))
+			_ = logger.Sync()
+		}
 		os.Exit(1)
 	}
 }
@@ -2,8 +2,11 @@ package models
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -13,15 +16,36 @@ import (
 const (
 	// ToDoTableName
 	ToDoTableName = "ToDo"
+
+	// ToDoTenantColumn column every todo query is scoped by
+	ToDoTenantColumn = "tenant"
 )
 
+// ErrInvalidReminder - A listed todo has a reminder no cursor can be issued for
+var ErrInvalidReminder = errors.New("invalid reminder")
+
 // ToDo ...
 type ToDo struct {
 	mymodel.Model `db:"-"`
-	ID            int64  `db:"id"`
+	ID            int64  `db:"id,omitempty"`
 	Title         string `db:"title"`
 	Description   string `db:"description"`
 	Reminder      string `db:"reminder"`
+	Tenant        string `db:"tenant"` // owner of the todo, set from the context on insert
+}
+
+// ToDoFilter - Optional filters applied when listing todos
+type ToDoFilter struct {
+	TitleContains  string
+	ReminderBefore time.Time
+	ReminderAfter  time.Time
+}
+
+// ToDoCursor - Keyset position of the last todo of a page
+type ToDoCursor struct {
+	Reminder string `json:"r"`
+	ID       int64  `json:"i"`
+	Filter   string `json:"f"` // fingerprint of the filter the cursor was issued for
 }
 
 // NewToDo ...
@@ -35,13 +59,24 @@ func NewToDo(ctx context.Context, db *sql.DB) (*ToDo, error) {
 		Offset:    0,
 		SortOrder: nil,
 		TableName: ToDoTableName,
+		Columns:   mymodel.ColumnNames(ToDo{}),
+
+		TenantColumn: ToDoTenantColumn,
 	}}
 
 	return &toDo, nil
 }
 
+// WithTx - Copy of the todo model running its queries in the transaction tx is bound to
+func (t *ToDo) WithTx(tx *mymodel.Model) *ToDo {
+	c := *t
+	c.Tx = tx.Tx
+
+	return &c
+}
+
 // AddTodo ...
-func (t *ToDo) AddTodo(title string, desc string, reminder time.Time) (res sql.Result, err error) {
+func (t *ToDo) AddTodo(ctx context.Context, title string, desc string, reminder time.Time) (res sql.Result, err error) {
 	insertSet := []ToDo{
 		{
 			Title:       title,
@@ -50,33 +85,120 @@ func (t *ToDo) AddTodo(title string, desc string, reminder time.Time) (res sql.R
 		},
 	}
 
-	return t.Insert(insertSet)
+	return t.Insert(ctx, insertSet)
 }
 
 // GetTodoByID ...
-func (t *ToDo) GetTodoByID(id int64) (todo ToDo, err error) {
+func (t *ToDo) GetTodoByID(ctx context.Context, id int64) (todo ToDo, err error) {
+	var (
+		todos []ToDo
+	)
+
+	err = t.Select(ctx, &todos, t.byID(id))
+	if err != nil {
+		return
+	}
+
+	if len(todos) == 0 {
+		err = sql.ErrNoRows
+		return
+	}
+
+	todo = todos[0]
+
+	return
+}
+
+// GetTodos ...
+func (t *ToDo) GetTodos(ctx context.Context) (todos []ToDo, err error) {
+	err = t.Select(ctx, &todos, nil)
+	return
+}
+
+// ListTodos - List up to limit todos ordered by reminder and id, starting after the cursor.
+// The returned cursor is nil when there is no further page
+func (t *ToDo) ListTodos(ctx context.Context, filter ToDoFilter, after *ToDoCursor, limit int) (todos []ToDo, next *ToDoCursor, err error) {
 	var (
-		todos      []ToDo
 		conditions mymodel.Conditions
 	)
 
-	conditions = []mymodel.Condition{
-		{
-			Combine:  "",
-			Field:    "id",
-			Operator: mymodel.OperatorEqual,
-			Value:    id,
-		},
+	if filter.TitleContains != "" {
+		conditions = conditions.And("title", mymodel.OperatorLike, "%"+mymodel.EscapeLike(filter.TitleContains)+"%")
 	}
 
-	err = t.Select(&todos, conditions)
-	if err != nil {
+	if !filter.ReminderBefore.IsZero() {
+		conditions = conditions.And("reminder", mymodel.OperatorLessThan, filter.ReminderBefore.Format(mymodel.SQLDatetime))
+	}
+
+	if !filter.ReminderAfter.IsZero() {
+		conditions = conditions.And("reminder", mymodel.OperatorGreaterThan, filter.ReminderAfter.Format(mymodel.SQLDatetime))
+	}
+
+	if after != nil {
+		if after.Filter != filter.fingerprint() {
+			err = errors.New(mymodel.InvalidPageToken)
+			return
+		}
+
+		// keyset: (reminder > ? OR (reminder = ? AND id > ?))
+		conditions = conditions.AndGroup(
+			mymodel.Where("reminder", mymodel.OperatorGreaterThan, after.Reminder).
+				OrGroup(mymodel.Where("reminder", mymodel.OperatorEqual, after.Reminder).
+					And("id", mymodel.OperatorGreaterThan, after.ID)),
+		)
+	}
+
+	// fetch one extra row to know if there is a next page
+	t.SortOrder = []string{"-reminder", "-id"}
+	t.Limit = limit + 1
+	t.Offset = mymodel.MinOffset
+
+	if err = t.Select(ctx, &todos, conditions); err != nil {
 		return
 	}
 
-	if len(todos) > 0 {
-		todo = todos[0]
+	if len(todos) > limit {
+		todos = todos[:limit]
+		last := todos[limit-1]
+		// the cursor is compared to the column, so it is kept in the column format whatever the connection returns
+		reminder, e := mymodel.ParseDatetime(last.Reminder)
+		if e != nil || reminder.IsZero() {
+			// a zero cursor would restart the listing or skip rows
+			return nil, nil, fmt.Errorf("%w '%s' of todo %d", ErrInvalidReminder, last.Reminder, last.ID)
+		}
+		next = &ToDoCursor{
+			Reminder: reminder.Format(mymodel.SQLDatetime),
+			ID:       last.ID,
+			Filter:   filter.fingerprint(),
+		}
 	}
 
 	return
 }
+
+// fingerprint - Digest of the filter so a cursor can not be replayed with a different filter
+func (f ToDoFilter) fingerprint() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d", f.TitleContains, f.ReminderBefore.Unix(), f.ReminderAfter.Unix())))
+	return hex.EncodeToString(sum[:8])
+}
+
+// UpdateTodo ...
+func (t *ToDo) UpdateTodo(ctx context.Context, id int64, title string, desc string, reminder time.Time) (res sql.Result, err error) {
+	set := map[string]interface{}{
+		"title":       title,
+		"description": desc,
+		"reminder":    reminder.Format(mymodel.SQLDatetime),
+	}
+
+	return t.Update(ctx, set, t.byID(id))
+}
+
+// DeleteTodo ...
+func (t *ToDo) DeleteTodo(ctx context.Context, id int64) (res sql.Result, err error) {
+	return t.Delete(ctx, t.byID(id))
+}
+
+// byID ...
+func (t *ToDo) byID(id int64) mymodel.Conditions {
+	return mymodel.Where("id", mymodel.OperatorEqual, id)
+}
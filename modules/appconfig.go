@@ -0,0 +1,351 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/sarulabs/di"
+	"github.com/spf13/viper"
+)
+
+const (
+	InstConfig = "primary_typed_config"
+
+	ConfigKeyAppPort         = "app.port"
+	ConfigKeyReflection      = "app.reflection"
+	ConfigKeyInterceptors    = "app.interceptors"
+	ConfigKeyTimingSlow      = "app.timing.slow"
+	ConfigKeyHealthInterval  = "app.health.interval"
+	ConfigKeyHealthTimeout   = "app.health.timeout"
+	ConfigKeyShutdownTimeout = "app.shutdown.timeout"
+	ConfigKeyServices        = "app.services"
+
+	// Built in interceptors
+	InterceptorRequestID = "requestid"
+	InterceptorLogging   = "logging"
+	InterceptorRecovery  = "recovery"
+	InterceptorTiming    = "timing"
+	InterceptorAuth      = "auth"
+	InterceptorAuthz     = "authz"
+	InterceptorMetrics   = "metrics"
+	InterceptorTracing   = "tracing"
+)
+
+const (
+	// DefaultAppPort port of the grpc server
+	DefaultAppPort = 3000
+
+	// DefaultTimingSlow calls slower than this are logged
+	DefaultTimingSlow = time.Second
+
+	// DefaultHealthInterval time between two database probes
+	DefaultHealthInterval = 10 * time.Second
+
+	// DefaultHealthTimeout time a database probe may take
+	DefaultHealthTimeout = 2 * time.Second
+
+	// DefaultShutdownTimeout time the calls in flight get to finish before they are cancelled
+	DefaultShutdownTimeout = 15 * time.Second
+)
+
+// DefaultInterceptors - Built in interceptors enabled when the config does not list any, outermost first.
+// Calls are authenticated and authorized unless the config leaves auth and authz out. Tracing and metrics are
+// opt-in, the config lists them
+var DefaultInterceptors = []string{
+	InterceptorRequestID,
+	InterceptorLogging,
+	InterceptorRecovery,
+	InterceptorAuth,
+	InterceptorAuthz,
+	InterceptorTiming,
+}
+
+// AppConfig - Typed settings under the app key of the config, durations are read as 500ms, 30s, 5m...
+type AppConfig struct {
+	Port       int
+	Reflection bool
+	// Interceptors is nil when the config does not list any, InterceptorChain then gives the defaults
+	Interceptors []string
+
+	TLS        TLSSettings
+	Database   DatabaseSettings
+	Pagination PaginationSettings
+	Log        LogSettings
+	Auth       AuthSettings
+	Authz      AuthzSettings
+	Timing     TimingSettings
+	Metrics    MetricsSettings
+	Tracing    TracingSettings
+	Health     HealthSettings
+	Shutdown   ShutdownSettings
+	// Services by name, the ones not listed are enabled
+	Services map[string]ServiceSettings
+}
+
+// TLSSettings - Server certificate, a client CA bundle turns on mutual TLS
+type TLSSettings struct {
+	Enabled    bool
+	Cert       string
+	Key        string
+	MinVersion string
+	Ciphers    []string
+	ClientCA   string
+}
+
+// DatabaseSettings - Connection and pool of the primary database, and the startup ping
+type DatabaseSettings struct {
+	DatabaseConfig `mapstructure:",squash"`
+	Ping           PingPolicy
+}
+
+// PaginationSettings - Secret signing the page tokens
+type PaginationSettings struct {
+	Secret string
+}
+
+// LogSettings - Level and encoding of the logger
+type LogSettings struct {
+	Level    string
+	Encoding string
+}
+
+// AuthSettings - Methods let through without credentials and the credentials accepted
+type AuthSettings struct {
+	Exempt  []string
+	JWT     JWTSettings
+	APIKeys []APIKey
+}
+
+// JWTSettings - Verification of bearer tokens
+type JWTSettings struct {
+	Enabled  bool
+	JWKS     string
+	Audience string
+	Leeway   time.Duration
+}
+
+// AuthzSettings - Policy file of the authorizer
+type AuthzSettings struct {
+	Policy string
+}
+
+// TimingSettings - Calls slower than Slow are logged
+type TimingSettings struct {
+	Slow time.Duration
+}
+
+// MetricsSettings - HTTP endpoint of the prometheus metrics
+type MetricsSettings struct {
+	Enabled bool
+	Port    int
+	Path    string
+}
+
+// TracingSettings - Exporter and sampling of the spans
+type TracingSettings struct {
+	Exporter    string
+	File        string
+	SampleRatio float64
+}
+
+// HealthSettings - Database probe of the health service, 0 uses the defaults
+type HealthSettings struct {
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// ShutdownSettings - Time the calls in flight get to finish once the server stops, 0 uses the default
+type ShutdownSettings struct {
+	Timeout time.Duration
+}
+
+// ServiceSettings - Switch of a grpc service, a missing switch enables it
+type ServiceSettings struct {
+	Enabled *bool
+}
+
+// ServiceEnabled - Whether the service of the name is registered, the config keys are lower case
+func (cfg AppConfig) ServiceEnabled(name string) bool {
+	s, ok := cfg.Services[strings.ToLower(name)]
+
+	return !ok || s.Enabled == nil || *s.Enabled
+}
+
+// InterceptorChain - Built in interceptors of the config, outermost first, DefaultInterceptors when it lists none
+func (cfg AppConfig) InterceptorChain() []string {
+	if cfg.Interceptors == nil {
+		return DefaultInterceptors
+	}
+
+	return cfg.Interceptors
+}
+
+// interceptorEnabled - Whether the built in interceptor is in the chain
+func (cfg AppConfig) interceptorEnabled(name string) bool {
+	for _, n := range cfg.InterceptorChain() {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InitConfig - Initialize the typed startup config and store in container, it fails with every invalid setting
+func InitConfig(builder *di.Builder) (err error) {
+
+	err = builder.Add(
+		di.Def{
+			Name:  InstConfig,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				store, err := ctn.SafeGet(InstConfigStore)
+				if err != nil {
+					return nil, err
+				}
+
+				return LoadAppConfig(store.(*ConfigStore).Config())
+			},
+		})
+
+	return
+}
+
+// LoadAppConfig - Decode the app settings of the config and validate them, the error lists every problem
+func LoadAppConfig(c *viper.Viper) (cfg *AppConfig, err error) {
+	var raw struct {
+		App AppConfig
+	}
+
+	hook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(mapstructure.StringToTimeDurationHookFunc(), stringToListHook))
+	if err = c.Unmarshal(&raw, hook); err != nil {
+		return nil, fmt.Errorf("invalid config-> %v", err)
+	}
+
+	if err = raw.App.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &raw.App, nil
+}
+
+// ValidateAppConfig - ConfigValidator decoding and validating the app settings
+func ValidateAppConfig(c *viper.Viper) error {
+	_, err := LoadAppConfig(c)
+
+	return err
+}
+
+// Validate - Check every setting, the error lists all the invalid ones
+func (cfg AppConfig) Validate() error {
+	var problems []string
+
+	invalid := func(key string, format string, args ...interface{}) {
+		problems = append(problems, key+" "+fmt.Sprintf(format, args...))
+	}
+	failed := func(err error) {
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		invalid(ConfigKeyAppPort, "should be between 1 and 65535, got %d", cfg.Port)
+	}
+
+	if cfg.TLS.Enabled {
+		if cfg.TLS.Cert == "" || cfg.TLS.Key == "" {
+			invalid(ConfigKeyTLSCert, "and %s are required when TLS is enabled", ConfigKeyTLSKey)
+		}
+		_, err := baseTLSConfig(cfg.TLS.MinVersion, cfg.TLS.Ciphers)
+		failed(err)
+	}
+
+	problems = append(problems, cfg.Database.problems()...)
+	failed(cfg.Database.Ping.Validate())
+
+	_, err := ParseLogLevel(cfg.Log.Level)
+	failed(err)
+	if e := cfg.Log.Encoding; e != "" && e != LogEncodingJSON && e != LogEncodingConsole {
+		invalid(ConfigKeyLogEncoding, "should be %s or %s, got '%s'", LogEncodingJSON, LogEncodingConsole, e)
+	}
+
+	// an authenticator without provider would reject every call
+	if cfg.interceptorEnabled(InterceptorAuth) && !cfg.Auth.JWT.Enabled && len(cfg.Auth.APIKeys) == 0 {
+		invalid(ConfigKeyInterceptors, "enables %s with no auth provider, set %s or add %s", InterceptorAuth, ConfigKeyAuthJWTEnabled, ConfigKeyAuthAPIKeys)
+	}
+	if cfg.Auth.JWT.Enabled && cfg.Auth.JWT.JWKS == "" {
+		invalid(ConfigKeyAuthJWTJWKS, "is required when JWT is enabled")
+	}
+	if cfg.Auth.JWT.Leeway < 0 {
+		invalid(ConfigKeyAuthJWTLeeway, "should not be negative, got %s", cfg.Auth.JWT.Leeway)
+	}
+	_, err = NewAPIKeyProvider(cfg.Auth.APIKeys)
+	failed(err)
+
+	if cfg.interceptorEnabled(InterceptorAuthz) && cfg.Authz.Policy == "" {
+		invalid(ConfigKeyAuthzPolicy, "is required when the %s interceptor is enabled", InterceptorAuthz)
+	}
+
+	if cfg.Timing.Slow < 0 {
+		invalid(ConfigKeyTimingSlow, "should not be negative, got %s", cfg.Timing.Slow)
+	}
+
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Port < 1 || cfg.Metrics.Port > 65535 {
+			invalid(ConfigKeyMetricsPort, "should be between 1 and 65535, got %d", cfg.Metrics.Port)
+		} else if cfg.Metrics.Port == cfg.Port {
+			invalid(ConfigKeyMetricsPort, "%d is the port of the grpc server", cfg.Metrics.Port)
+		}
+		if !strings.HasPrefix(cfg.Metrics.Path, "/") {
+			invalid(ConfigKeyMetricsPath, "should start with /, got '%s'", cfg.Metrics.Path)
+		}
+	}
+
+	switch cfg.Tracing.Exporter {
+	case "", TracingExporterStdout:
+	case TracingExporterFile:
+		if cfg.Tracing.File == "" {
+			invalid(ConfigKeyTracingFile, "is required by the %s exporter", TracingExporterFile)
+		}
+	default:
+		invalid(ConfigKeyTracingExporter, "should be %s or %s, got '%s'", TracingExporterStdout, TracingExporterFile, cfg.Tracing.Exporter)
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		invalid(ConfigKeyTracingSampleRatio, "should be between 0 and 1, got %v", cfg.Tracing.SampleRatio)
+	}
+
+	if cfg.Health.Interval < 0 {
+		invalid(ConfigKeyHealthInterval, "should not be negative, got %s", cfg.Health.Interval)
+	}
+	if cfg.Health.Timeout < 0 {
+		invalid(ConfigKeyHealthTimeout, "should not be negative, got %s", cfg.Health.Timeout)
+	}
+
+	if cfg.Shutdown.Timeout < 0 {
+		invalid(ConfigKeyShutdownTimeout, "should not be negative, got %s", cfg.Shutdown.Timeout)
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid config: " + strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// stringToListHook - Split a string on commas and spaces when a list is expected,
+// lists set from the environment or the command line are strings
+func stringToListHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to.Kind() != reflect.Slice {
+		return data, nil
+	}
+
+	return strings.FieldsFunc(data.(string), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}), nil
+}
@@ -0,0 +1,186 @@
+package modules
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadAppConfig(t *testing.T) {
+	os.Setenv("GRPOC_APP_INTERCEPTORS", "requestid,logging recovery")
+	defer os.Unsetenv("GRPOC_APP_INTERCEPTORS")
+
+	c, err := NewConfig(ConfigSource{Path: "../configs"})
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+
+	cfg, err := LoadAppConfig(c)
+	if err != nil {
+		t.Fatalf("LoadAppConfig() of configs/config.yaml error = %v", err)
+	}
+
+	if cfg.Port != 3000 || cfg.Database.Host != "localhost" || cfg.Database.Ping.Attempts != 5 || cfg.Timing.Slow != time.Second {
+		t.Errorf("LoadAppConfig() = %+v", cfg)
+	}
+	// the sample config must not ship a known credential
+	if len(cfg.Auth.APIKeys) != 0 {
+		t.Errorf("LoadAppConfig() api keys = %+v, want none", cfg.Auth.APIKeys)
+	}
+	// nor accept the development token
+	if cfg.Auth.JWT.Enabled || cfg.Auth.JWT.JWKS != "" {
+		t.Errorf("LoadAppConfig() jwt = %+v, want it off", cfg.Auth.JWT)
+	}
+	if want := []string{"requestid", "logging", "recovery"}; !reflect.DeepEqual(cfg.Interceptors, want) {
+		t.Errorf("LoadAppConfig() interceptors = %v, want %v from the environment", cfg.Interceptors, want)
+	}
+}
+
+func TestLoadAppConfig_development(t *testing.T) {
+	// the sample config alone has no auth provider
+	c, err := NewConfig(ConfigSource{Path: "../configs"})
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	if _, err = LoadAppConfig(c); err == nil || !strings.Contains(err.Error(), "no auth provider") {
+		t.Errorf("LoadAppConfig() error = %v, want the missing auth provider", err)
+	}
+
+	// the overrides of the development setup of the README
+	c, err = NewConfig(ConfigSource{Path: "../configs", Overrides: ConfigOverrides{
+		ConfigKeyAuthJWTEnabled: "true",
+		ConfigKeyAuthJWTJWKS:    "./configs/dev/jwks.json",
+	}})
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	cfg, err := LoadAppConfig(c)
+	if err != nil {
+		t.Fatalf("LoadAppConfig() error = %v", err)
+	}
+	if !cfg.Auth.JWT.Enabled || cfg.Auth.JWT.JWKS != "./configs/dev/jwks.json" {
+		t.Errorf("LoadAppConfig() jwt = %+v, want the development key set", cfg.Auth.JWT)
+	}
+	// the trace of a call starts before its logs
+	if chain := cfg.InterceptorChain(); len(chain) < 2 || chain[0] != InterceptorRequestID || chain[1] != InterceptorTracing {
+		t.Errorf("LoadAppConfig() interceptors = %v, want %s after %s", chain, InterceptorTracing, InterceptorRequestID)
+	}
+}
+
+func TestLoadAppConfig_environment(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// keys without a default nor a file value, AutomaticEnv alone does not know them
+	env := map[string]string{
+		"GRPOC_APP_PORT":                   "3001",
+		"GRPOC_APP_DATABASE_PASSWORD":      "secret",
+		"GRPOC_APP_DATABASE_PING_ATTEMPTS": "0",
+		"GRPOC_APP_PAGINATION_SECRET":      "page-secret",
+		"GRPOC_APP_AUTH_JWT_ENABLED":       "true",
+		"GRPOC_APP_AUTH_JWT_JWKS":          "/etc/jwks.json",
+		"GRPOC_APP_AUTHZ_POLICY":           "/etc/policy.yaml",
+		"GRPOC_APP_TRACING_FILE":           "/tmp/spans.json",
+		"GRPOC_APP_SHUTDOWN_TIMEOUT":       "20s",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	c, err := NewConfig(ConfigSource{Path: dir})
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+
+	cfg, err := LoadAppConfig(c)
+	if err != nil {
+		t.Fatalf("LoadAppConfig() error = %v", err)
+	}
+
+	if cfg.Port != 3001 || cfg.Database.Password != "secret" || cfg.Database.Ping.Attempts != 0 || cfg.Pagination.Secret != "page-secret" ||
+		!cfg.Auth.JWT.Enabled || cfg.Auth.JWT.JWKS != "/etc/jwks.json" || cfg.Authz.Policy != "/etc/policy.yaml" || cfg.Tracing.File != "/tmp/spans.json" || cfg.Shutdown.Timeout != 20*time.Second {
+		t.Errorf("LoadAppConfig() = %+v, want the values of the environment", cfg)
+	}
+}
+
+func TestAppConfig_Validate(t *testing.T) {
+	c := viper.New()
+	for k, v := range ConfigDefaults {
+		c.SetDefault(k, v)
+	}
+	settings := map[string]interface{}{
+		ConfigKeyAppPort:            0,
+		ConfigKeyDbPort:             "3306",
+		ConfigKeyDbPingTimeout:      "0s",
+		ConfigKeyLogLevel:           "loud",
+		ConfigKeyTLSEnabled:         true,
+		ConfigKeyMetricsEnabled:     true,
+		ConfigKeyMetricsPath:        "metrics",
+		ConfigKeyTracingSampleRatio: 2,
+		ConfigKeyHealthInterval:     "-1s",
+	}
+	for k, v := range settings {
+		c.Set(k, v)
+	}
+
+	_, err := LoadAppConfig(c)
+	if err == nil {
+		t.Fatal("LoadAppConfig() error = nil, want the invalid settings")
+	}
+
+	for _, key := range []string{ConfigKeyAppPort, ConfigKeyDbPingTimeout, ConfigKeyLogLevel, ConfigKeyTLSCert, ConfigKeyMetricsPath, ConfigKeyTracingSampleRatio, ConfigKeyHealthInterval} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("LoadAppConfig() error = %v, want a problem with %s", err, key)
+		}
+	}
+	if strings.Contains(err.Error(), ConfigKeyDbPort) {
+		t.Errorf("LoadAppConfig() error = %v, want the string port converted", err)
+	}
+}
+
+func TestAppConfig_Validate_authProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     func(cfg *AppConfig)
+		wantErr bool
+	}{
+		{name: "No provider", cfg: func(cfg *AppConfig) {}, wantErr: true},
+		{name: "JWT", cfg: func(cfg *AppConfig) { cfg.Auth.JWT = JWTSettings{Enabled: true, JWKS: "jwks.json"} }},
+		{name: "API key", cfg: func(cfg *AppConfig) { cfg.Auth.APIKeys = []APIKey{{Key: "key", Subject: "dev"}} }},
+		{name: "Auth interceptor off", cfg: func(cfg *AppConfig) { cfg.Interceptors = []string{InterceptorRequestID} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := viper.New()
+			for k, v := range ConfigDefaults {
+				c.SetDefault(k, v)
+			}
+			c.Set(ConfigKeyAppPort, 3000)
+			c.Set(ConfigKeyInterceptors, InterceptorRequestID)
+
+			cfg, err := LoadAppConfig(c)
+			if err != nil {
+				t.Fatalf("LoadAppConfig() error = %v", err)
+			}
+			cfg.Interceptors = []string{InterceptorRequestID, InterceptorAuth}
+			tt.cfg(cfg)
+
+			err = cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "no auth provider") {
+				t.Errorf("Validate() error = %v, want the missing provider", err)
+			}
+		})
+	}
+}
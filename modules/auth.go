@@ -0,0 +1,238 @@
+package modules
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sarulabs/di"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	mymodel "grpoc/modules/model"
+)
+
+const (
+	InstAuth = "primary_auth"
+
+	ConfigKeyAuthExempt      = "app.auth.exempt"
+	ConfigKeyAuthAPIKeys     = "app.auth.apikeys"
+	ConfigKeyAuthJWTEnabled  = "app.auth.jwt.enabled"
+	ConfigKeyAuthJWTJWKS     = "app.auth.jwt.jwks"
+	ConfigKeyAuthJWTAudience = "app.auth.jwt.audience"
+	ConfigKeyAuthJWTLeeway   = "app.auth.jwt.leeway"
+
+	// MetadataKeyAuthorization carries the bearer token of the caller
+	MetadataKeyAuthorization = "authorization"
+	// MetadataKeyAPIKey carries the API key of the caller
+	MetadataKeyAPIKey = "x-api-key"
+)
+
+// ErrNoCredentials - Returned by a provider when the call carries none of its credentials, the next provider is tried
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal - Authenticated caller of a call. Tenant owns the rows the caller can reach, the subject when not given
+type Principal struct {
+	Subject  string
+	Tenant   string
+	Roles    []string
+	Scopes   []string
+	Provider string
+}
+
+// AuthProvider - Authenticates a call from its metadata
+type AuthProvider interface {
+	// Authenticate returns ErrNoCredentials when the metadata has no credentials for the provider
+	// and any other error when they are invalid
+	Authenticate(ctx context.Context, md metadata.MD) (*Principal, error)
+}
+
+// principalKey is the context key of the principal
+type principalKey struct{}
+
+// WithPrincipal - Store the authenticated principal in the context
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom - Get the authenticated principal of the call, false when the call is not authenticated
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// Authenticator - Authenticates calls with the first provider the call has credentials for
+type Authenticator struct {
+	providers []AuthProvider
+	exempt    []string
+	logger    *zap.Logger
+}
+
+// InitAuth - Initialize the authenticator from the config and store in container, only used when the auth interceptor is enabled
+func InitAuth(builder *di.Builder) (err error) {
+
+	err = builder.Add(
+		di.Def{
+			Name:  InstAuth,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				var (
+					cfg       = ctn.Get(InstConfig).(*AppConfig).Auth
+					providers []AuthProvider
+				)
+
+				if cfg.JWT.Enabled {
+					var jwt *JWTProvider
+					if jwt, e = NewJWTProvider(cfg.JWT.JWKS, cfg.JWT.Audience, cfg.JWT.Leeway); e != nil {
+						return
+					}
+					providers = append(providers, jwt)
+				}
+
+				if len(cfg.APIKeys) > 0 {
+					var p *APIKeyProvider
+					if p, e = NewAPIKeyProvider(cfg.APIKeys); e != nil {
+						return
+					}
+					providers = append(providers, p)
+				}
+
+				return NewAuthenticator(providers, cfg.Exempt, ctn.Get(InstLogger).(*zap.Logger))
+			},
+		})
+
+	return
+}
+
+// NewAuthenticator - Authenticator trying the providers in order, calls of the exempt methods are let through.
+// Exempt methods are full method names, or a prefix ending with * like /grpc.health.v1.Health/*
+func NewAuthenticator(providers []AuthProvider, exempt []string, logger *zap.Logger) (*Authenticator, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no auth provider configured, enable %s or add %s", ConfigKeyAuthJWTEnabled, ConfigKeyAuthAPIKeys)
+	}
+
+	return &Authenticator{providers: providers, exempt: exempt, logger: logger}, nil
+}
+
+// UnaryInterceptor - Reject unary calls which are not authenticated with codes.Unauthenticated
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor - Reject streams which are not authenticated with codes.Unauthenticated
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate - Context with the principal of the call. The reason of a failure is only logged,
+// the caller gets a generic message
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if MatchMethod(a.exempt, method) {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	logger := Logger(ctx, a.logger)
+
+	for _, provider := range a.providers {
+		p, err := provider.Authenticate(ctx, md)
+		if err == ErrNoCredentials {
+			continue
+		}
+		if err != nil {
+			logger.Info("Authentication failed", zap.Error(err))
+			return ctx, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+
+		ctx = WithLogger(WithPrincipal(ctx, p), logger.With(zap.String("subject", p.Subject), zap.String("tenant", p.Tenant)))
+		return mymodel.WithTenant(ctx, p.Tenant), nil
+	}
+
+	return ctx, status.Error(codes.Unauthenticated, "missing credentials")
+}
+
+// MatchMethod - Whether the full method name matches one of the patterns, a pattern ending with * matches by prefix
+func MatchMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if pattern == method || pattern == "*" {
+			return true
+		}
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(method, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// APIKey - Static API key and the principal it authenticates as
+type APIKey struct {
+	Key     string   `mapstructure:"key"`
+	Subject string   `mapstructure:"subject"`
+	Tenant  string   `mapstructure:"tenant"`
+	Roles   []string `mapstructure:"roles"`
+	Scopes  []string `mapstructure:"scopes"`
+}
+
+// APIKeyProvider - Authenticates the x-api-key metadata against static keys
+type APIKeyProvider struct {
+	// principals by the hash of their key, so a lookup does not compare the keys byte by byte
+	principals map[[sha256.Size]byte]*Principal
+}
+
+// NewAPIKeyProvider - Provider of the keys, every key needs a subject
+func NewAPIKeyProvider(keys []APIKey) (*APIKeyProvider, error) {
+	p := &APIKeyProvider{principals: make(map[[sha256.Size]byte]*Principal, len(keys))}
+
+	for i, k := range keys {
+		if k.Key == "" || k.Subject == "" {
+			return nil, fmt.Errorf("%s[%d] needs a key and a subject", ConfigKeyAuthAPIKeys, i)
+		}
+		tenant := k.Tenant
+		if tenant == "" {
+			tenant = k.Subject
+		}
+		p.principals[sha256.Sum256([]byte(k.Key))] = &Principal{
+			Subject:  k.Subject,
+			Tenant:   tenant,
+			Roles:    k.Roles,
+			Scopes:   k.Scopes,
+			Provider: "apikey",
+		}
+	}
+
+	return p, nil
+}
+
+// Authenticate - Principal of the API key of the call
+func (p *APIKeyProvider) Authenticate(ctx context.Context, md metadata.MD) (*Principal, error) {
+	keys := md.Get(MetadataKeyAPIKey)
+	if len(keys) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	principal, ok := p.principals[sha256.Sum256([]byte(keys[0]))]
+	if !ok {
+		return nil, errors.New("unknown API key")
+	}
+
+	return principal, nil
+}
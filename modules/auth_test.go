@@ -0,0 +1,207 @@
+package modules
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	mymodel "grpoc/modules/model"
+)
+
+var (
+	testSecret = []byte("test-secret")
+	testRSA, _ = rsa.GenerateKey(rand.Reader, 2048)
+	testNow    = time.Unix(1564675200, 0)
+)
+
+// signJWT signs the claims with the key, a []byte for HS256 or an *rsa.PrivateKey for RS256
+func signJWT(t *testing.T, alg string, kid string, key interface{}, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sum := sha256.Sum256([]byte(signed))
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// testJWKS is a key set with the HS256 secret as kid hs and the RS256 public key as kid rs
+func testJWKS() []byte {
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "oct", "kid": "hs", "k": base64.RawURLEncoding.EncodeToString(testSecret)},
+			{
+				"kty": "RSA",
+				"kid": "rs",
+				"n":   base64.RawURLEncoding.EncodeToString(testRSA.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(testRSA.E)).Bytes()),
+			},
+		},
+	})
+
+	return jwks
+}
+
+func TestJWTProvider_Authenticate(t *testing.T) {
+	keys, err := parseJWKS(testJWKS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &JWTProvider{keys: keys, audience: "grpoc", leeway: time.Minute, now: func() time.Time { return testNow }}
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{"sub": "alice", "aud": "grpoc", "exp": testNow.Add(time.Hour).Unix(), "roles": []string{"editor"}, "scope": "todo:read todo:write"}
+	}
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    *Principal
+		wantErr bool
+	}{
+		{
+			name:  "HS256",
+			token: signJWT(t, JWTAlgHS256, "hs", testSecret, valid()),
+			want:  &Principal{Subject: "alice", Tenant: "alice", Roles: []string{"editor"}, Scopes: []string{"todo:read", "todo:write"}, Provider: "jwt"},
+		},
+		{
+			name:  "RS256 with audience list",
+			token: signJWT(t, JWTAlgRS256, "rs", testRSA, with("aud", []string{"other", "grpoc"})),
+			want:  &Principal{Subject: "alice", Tenant: "alice", Roles: []string{"editor"}, Scopes: []string{"todo:read", "todo:write"}, Provider: "jwt"},
+		},
+		{
+			name:  "Tenant claim",
+			token: signJWT(t, JWTAlgHS256, "hs", testSecret, with("tenant", "acme")),
+			want:  &Principal{Subject: "alice", Tenant: "acme", Roles: []string{"editor"}, Scopes: []string{"todo:read", "todo:write"}, Provider: "jwt"},
+		},
+		{name: "Expired within leeway", token: signJWT(t, JWTAlgHS256, "hs", testSecret, with("exp", testNow.Add(-30*time.Second).Unix())), want: &Principal{Subject: "alice", Tenant: "alice", Roles: []string{"editor"}, Scopes: []string{"todo:read", "todo:write"}, Provider: "jwt"}},
+		{name: "Expired", token: signJWT(t, JWTAlgHS256, "hs", testSecret, with("exp", testNow.Add(-time.Hour).Unix())), wantErr: true},
+		{name: "No expiry", token: signJWT(t, JWTAlgHS256, "hs", testSecret, with("exp", nil)), wantErr: true},
+		{name: "Not valid yet", token: signJWT(t, JWTAlgHS256, "hs", testSecret, with("nbf", testNow.Add(time.Hour).Unix())), wantErr: true},
+		{name: "Other audience", token: signJWT(t, JWTAlgHS256, "hs", testSecret, with("aud", "other")), wantErr: true},
+		{name: "No subject", token: signJWT(t, JWTAlgHS256, "hs", testSecret, with("sub", nil)), wantErr: true},
+		{name: "Wrong secret", token: signJWT(t, JWTAlgHS256, "hs", []byte("other"), valid()), wantErr: true},
+		{name: "Unknown key id", token: signJWT(t, JWTAlgHS256, "other", testSecret, valid()), wantErr: true},
+		{name: "Algorithm of another key", token: signJWT(t, JWTAlgHS256, "rs", testSecret, valid()), wantErr: true},
+		{name: "Malformed", token: "not.a.token", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Authenticate(context.Background(), metadata.Pairs(MetadataKeyAuthorization, "Bearer "+tt.token))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Authenticate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := p.Authenticate(context.Background(), metadata.MD{}); err != ErrNoCredentials {
+		t.Errorf("Authenticate() without token error = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestJWTProvider_developmentToken(t *testing.T) {
+	// the development key set verifies the shipped development token
+	p, err := NewJWTProvider("../configs/dev/jwks.json", "grpoc", 0)
+	if err != nil {
+		t.Fatalf("NewJWTProvider() error = %v", err)
+	}
+
+	token, err := ioutil.ReadFile("../configs/dev/dev.jwt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal, err := p.Authenticate(context.Background(), metadata.Pairs(MetadataKeyAuthorization, "Bearer "+strings.TrimSpace(string(token))))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if principal.Subject != "local-dev" || principal.Tenant != "local-dev" || !containsAny(principal.Roles, []string{"admin"}) {
+		t.Errorf("Authenticate() = %+v, want the local-dev admin", principal)
+	}
+}
+
+func TestAuthenticator_UnaryInterceptor(t *testing.T) {
+	keys, err := NewAPIKeyProvider([]APIKey{{Key: "secret", Subject: "ci", Roles: []string{"admin"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAuthenticator([]AuthProvider{keys}, []string{"/grpc.health.v1.Health/*"}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		p, _ := PrincipalFrom(ctx)
+		if tenant, _ := mymodel.TenantFrom(ctx); p != nil && tenant != p.Tenant {
+			return nil, fmt.Errorf("tenant %s in context, want %s", tenant, p.Tenant)
+		}
+		return p, nil
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		md          metadata.MD
+		wantSubject string
+		wantCode    codes.Code
+	}{
+		{name: "API key", method: "/todo.ToDoService/Read", md: metadata.Pairs(MetadataKeyAPIKey, "secret"), wantSubject: "ci"},
+		{name: "Unknown API key", method: "/todo.ToDoService/Read", md: metadata.Pairs(MetadataKeyAPIKey, "other"), wantCode: codes.Unauthenticated},
+		{name: "Missing credentials", method: "/todo.ToDoService/Read", md: metadata.MD{}, wantCode: codes.Unauthenticated},
+		{name: "Exempt method", method: "/grpc.health.v1.Health/Check", md: metadata.MD{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			resp, err := a.UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("UnaryInterceptor() error = %v, want code %v", err, tt.wantCode)
+			}
+			if p, _ := resp.(*Principal); tt.wantSubject != "" && (p == nil || p.Subject != tt.wantSubject) {
+				t.Errorf("UnaryInterceptor() principal = %+v, want subject %s", p, tt.wantSubject)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/sarulabs/di"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	InstAuthz = "primary_authz"
+
+	ConfigKeyAuthzPolicy = "app.authz.policy"
+
+	// policyKeyRules is the key of the rules in the policy file
+	policyKeyRules = "rules"
+)
+
+// PolicyRule - Who may call the methods matching the pattern. A principal needs one of the roles when roles are given
+// and every scope when scopes are given, a public rule lets any caller through, authenticated or not
+type PolicyRule struct {
+	Method string   `mapstructure:"method"`
+	Roles  []string `mapstructure:"roles"`
+	Scopes []string `mapstructure:"scopes"`
+	Public bool     `mapstructure:"public"`
+}
+
+// Authorizer - Checks calls against the rules of a policy file and reloads it when it changes on disk.
+// The first rule matching the method applies, a method without a rule is denied
+type Authorizer struct {
+	file      string
+	logger    *zap.Logger
+	stopWatch func()
+
+	mu    sync.RWMutex
+	rules []PolicyRule
+}
+
+// InitAuthz - Initialize the authorizer from the policy file and store in container, only used when the authz interceptor is enabled
+func InitAuthz(builder *di.Builder) (err error) {
+
+	err = builder.Add(
+		di.Def{
+			Name:  InstAuthz,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				return NewAuthorizer(
+					ctn.Get(InstConfig).(*AppConfig).Authz.Policy,
+					ctn.Get(InstWatcher).(*FileWatcher),
+					ctn.Get(InstLogger).(*zap.Logger),
+				)
+			},
+			Close: func(obj interface{}) error {
+				return obj.(*Authorizer).Close()
+			},
+		})
+
+	return
+}
+
+// NewAuthorizer - Load the policy file and watch it for changes with the watcher
+func NewAuthorizer(file string, watcher *FileWatcher, logger *zap.Logger) (a *Authorizer, err error) {
+	if file == "" {
+		return nil, fmt.Errorf("%s is required when the authz interceptor is enabled", ConfigKeyAuthzPolicy)
+	}
+
+	a = &Authorizer{file: file, logger: logger}
+
+	if err = a.Reload(); err != nil {
+		return nil, err
+	}
+
+	if err = a.watch(watcher); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Reload - Load the policy file again, the loaded rules are kept when it is invalid
+func (a *Authorizer) Reload() error {
+	_, err := a.reload()
+
+	return err
+}
+
+// reload - Load the policy file again and tell whether its rules changed
+func (a *Authorizer) reload() (changed bool, err error) {
+	rules, err := LoadPolicy(a.file)
+	if err != nil {
+		return false, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	changed = !reflect.DeepEqual(a.rules, rules)
+	a.rules = rules
+
+	return
+}
+
+// Close - Stop watching the policy file
+func (a *Authorizer) Close() error {
+	a.stopWatch()
+
+	return nil
+}
+
+// UnaryInterceptor - Reject unary calls the policy does not allow with codes.PermissionDenied
+func (a *Authorizer) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := a.Authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor - Reject streams the policy does not allow with codes.PermissionDenied
+func (a *Authorizer) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.Authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// Authorize - Check the principal of the context against the rule of the method
+func (a *Authorizer) Authorize(ctx context.Context, method string) error {
+	rule, ok := a.rule(method)
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "no policy allows calling %s", method)
+	}
+
+	if rule.Public {
+		return nil
+	}
+
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return status.Errorf(codes.Unauthenticated, "%s requires an authenticated caller", method)
+	}
+
+	if len(rule.Roles) > 0 && !containsAny(p.Roles, rule.Roles) {
+		return status.Errorf(codes.PermissionDenied, "%s requires one of the roles %s", method, strings.Join(rule.Roles, ", "))
+	}
+
+	for _, scope := range rule.Scopes {
+		if !containsAny(p.Scopes, []string{scope}) {
+			return status.Errorf(codes.PermissionDenied, "%s requires the scope %s", method, scope)
+		}
+	}
+
+	return nil
+}
+
+// rule - First rule matching the method
+func (a *Authorizer) rule(method string) (PolicyRule, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, r := range a.rules {
+		if MatchMethod([]string{r.Method}, method) {
+			return r, true
+		}
+	}
+
+	return PolicyRule{}, false
+}
+
+// watch - Reload the policy when its file changes, which includes the symlink swaps of mounted ConfigMaps
+func (a *Authorizer) watch(watcher *FileWatcher) (err error) {
+	a.stopWatch, err = watcher.Watch("policy", func() {
+		changed, err := a.reload()
+		if err != nil {
+			a.logger.Error("Keeping the loaded policy", zap.String("policy", a.file), zap.Error(err))
+			return
+		}
+		if changed {
+			a.logger.Info("Reloaded policy", zap.String("policy", a.file))
+		}
+	}, a.file)
+
+	return
+}
+
+// LoadPolicy - Read and validate the rules of a policy file
+func LoadPolicy(file string) (rules []PolicyRule, err error) {
+	c := viper.New()
+	c.SetConfigFile(file)
+
+	if err = c.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to load policy-> %v", err)
+	}
+
+	if err = c.UnmarshalKey(policyKeyRules, &rules); err != nil {
+		return nil, fmt.Errorf("invalid policy %s-> %v", file, err)
+	}
+
+	for i, r := range rules {
+		if r.Method == "" {
+			return nil, fmt.Errorf("invalid policy %s, rule %d has no method", file, i)
+		}
+		if !r.Public && len(r.Roles) == 0 && len(r.Scopes) == 0 {
+			return nil, fmt.Errorf("invalid policy %s, rule %d for %s needs roles, scopes or public", file, i, r.Method)
+		}
+	}
+
+	return
+}
+
+// containsAny - Whether any of the wanted values is in the values
+func containsAny(values []string, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if v == w {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package modules
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testPolicy = `rules:
+  - method: /grpc.health.v1.Health/*
+    public: true
+  - method: /todo.ToDoService/Delete
+    roles: [editor]
+    scopes: [todo:write]
+  - method: /todo.ToDoService/*
+    roles: [viewer, editor]
+`
+
+// writePolicy writes the policy file into the directory
+func writePolicy(t *testing.T, dir string, policy string) string {
+	t.Helper()
+
+	file := filepath.Join(dir, "policy.yaml")
+	if err := ioutil.WriteFile(file, []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	watcher := testWatcher(t)
+	defer watcher.Close()
+
+	a, err := NewAuthorizer(writePolicy(t, dir, testPolicy), watcher, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuthorizer() error = %v", err)
+	}
+	defer a.Close()
+
+	editor := &Principal{Subject: "alice", Roles: []string{"editor"}, Scopes: []string{"todo:write"}}
+	viewer := &Principal{Subject: "bob", Roles: []string{"viewer"}}
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		method    string
+		wantCode  codes.Code
+	}{
+		{name: "Role and scope", principal: editor, method: "/todo.ToDoService/Delete", wantCode: codes.OK},
+		{name: "Missing role", principal: viewer, method: "/todo.ToDoService/Delete", wantCode: codes.PermissionDenied},
+		{name: "Missing scope", principal: &Principal{Roles: []string{"editor"}}, method: "/todo.ToDoService/Delete", wantCode: codes.PermissionDenied},
+		{name: "Prefix rule", principal: viewer, method: "/todo.ToDoService/Read", wantCode: codes.OK},
+		{name: "Public", method: "/grpc.health.v1.Health/Check", wantCode: codes.OK},
+		{name: "Not authenticated", method: "/todo.ToDoService/Read", wantCode: codes.Unauthenticated},
+		{name: "No rule", principal: editor, method: "/other.Service/Call", wantCode: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = WithPrincipal(ctx, tt.principal)
+			}
+			if err := a.Authorize(ctx, tt.method); status.Code(err) != tt.wantCode {
+				t.Errorf("Authorize() error = %v, want code %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_reload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := writePolicy(t, dir, testPolicy)
+	watcher := testWatcher(t)
+	defer watcher.Close()
+
+	a, err := NewAuthorizer(file, watcher, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAuthorizer() error = %v", err)
+	}
+	defer a.Close()
+
+	ctx := WithPrincipal(context.Background(), &Principal{Roles: []string{"viewer"}})
+
+	// an invalid policy keeps the loaded rules
+	writePolicy(t, dir, "rules:\n  - method: /todo.ToDoService/*\n")
+	if err := a.Reload(); err == nil {
+		t.Fatal("Reload() error = nil, want an error for a rule without roles")
+	}
+	if err := a.Authorize(ctx, "/todo.ToDoService/Read"); err != nil {
+		t.Fatalf("Authorize() after invalid reload error = %v", err)
+	}
+
+	writePolicy(t, dir, "rules:\n  - method: /todo.ToDoService/*\n    roles: [admin]\n")
+	for deadline := time.Now().Add(5 * time.Second); a.Authorize(ctx, "/todo.ToDoService/Read") == nil; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Authorize() kept the old policy")
+		}
+	}
+}
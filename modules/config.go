@@ -0,0 +1,144 @@
+package modules
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// EnvPrefix is the prefix of the environment variables overriding config keys,
+	// app.database.host is read from GRPOC_APP_DATABASE_HOST
+	EnvPrefix = "GRPOC"
+)
+
+// ConfigDefaults - Values of the app keys used when neither the file, the environment nor a flag sets them
+var ConfigDefaults = map[string]interface{}{
+	ConfigKeyAppPort:            DefaultAppPort,
+	ConfigKeyReflection:         false,
+	ConfigKeyTimingSlow:         DefaultTimingSlow,
+	ConfigKeyHealthInterval:     DefaultHealthInterval,
+	ConfigKeyHealthTimeout:      DefaultHealthTimeout,
+	ConfigKeyShutdownTimeout:    DefaultShutdownTimeout,
+	ConfigKeyDbHost:             "localhost",
+	ConfigKeyDbPort:             3306,
+	ConfigKeyDbUser:             "root",
+	ConfigKeyDbName:             "grpc_poc",
+	ConfigKeyDbMaxIdleConns:     DefaultDbMaxIdleConns,
+	ConfigKeyDbPingAttempts:     DefaultDbPingAttempts,
+	ConfigKeyDbPingBackoff:      DefaultDbPingBackoff,
+	ConfigKeyDbPingMaxBackoff:   DefaultDbPingMaxBackoff,
+	ConfigKeyDbPingTimeout:      DefaultDbPingTimeout,
+	ConfigKeyLogLevel:           DefaultLogLevel,
+	ConfigKeyLogEncoding:        LogEncodingJSON,
+	ConfigKeyTLSEnabled:         false,
+	ConfigKeyTLSMinVersion:      "1.2",
+	ConfigKeyAuthJWTEnabled:     false,
+	ConfigKeyMetricsEnabled:     false,
+	ConfigKeyMetricsPort:        9090,
+	ConfigKeyMetricsPath:        DefaultMetricsPath,
+	ConfigKeyTracingExporter:    TracingExporterStdout,
+	ConfigKeyTracingSampleRatio: 1,
+}
+
+// ConfigSource - Where the application config is read from.
+// Precedence is Overrides, then the environment, then the file, then the defaults
+type ConfigSource struct {
+	// Path is the directory of config.yaml, CONFIG_PATH or ./configs when empty
+	Path string
+	// Defaults of the keys the modules do not know about, they win over ConfigDefaults
+	Defaults map[string]interface{}
+	// Overrides are set from the command line
+	Overrides ConfigOverrides
+}
+
+// ConfigOverrides - Config keys set on the command line, it is a flag.Value taking key=value
+type ConfigOverrides map[string]string
+
+// String - Print the overrides sorted by key
+func (o ConfigOverrides) String() string {
+	pairs := make([]string, 0, len(o))
+	for k, v := range o {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// Set - Add a key=value override, lists take comma or space separated values
+func (o ConfigOverrides) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("invalid override '%s', want key=value", value)
+	}
+
+	o[strings.ToLower(strings.TrimSpace(parts[0]))] = parts[1]
+
+	return nil
+}
+
+// NewConfig - Read the config of the source. A missing config file is not an error,
+// the environment and the defaults are enough to run in a container
+func NewConfig(source ConfigSource) (c *viper.Viper, err error) {
+	c = viper.New()
+
+	for k, v := range ConfigDefaults {
+		c.SetDefault(k, v)
+	}
+	for k, v := range source.Defaults {
+		c.SetDefault(k, v)
+	}
+
+	c.SetEnvPrefix(EnvPrefix)
+	c.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	c.AutomaticEnv()
+	bindEnv(c, "app", reflect.TypeOf(AppConfig{}))
+
+	c.SetConfigName("config")
+	c.SetConfigType("yaml")
+	c.AddConfigPath(source.Path)
+
+	if err = c.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, err
+		}
+		err = nil
+	}
+
+	for k, v := range source.Overrides {
+		c.Set(k, v)
+	}
+
+	return
+}
+
+// bindEnv - Bind the environment variable of every key of the settings struct. AutomaticEnv only reads
+// the keys viper knows from the file or the defaults, Unmarshal would miss the other ones.
+// The keys of maps are not known up front, they are set in the file or with an override
+func bindEnv(c *viper.Viper, prefix string, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		key := prefix + "." + strings.ToLower(f.Name)
+		tag := strings.Split(f.Tag.Get("mapstructure"), ",")
+		if tag[0] != "" {
+			key = prefix + "." + tag[0]
+		}
+
+		switch {
+		case f.Type.Kind() == reflect.Struct && len(tag) > 1 && tag[1] == "squash":
+			bindEnv(c, prefix, f.Type)
+		case f.Type.Kind() == reflect.Struct:
+			bindEnv(c, key, f.Type)
+		case f.Type.Kind() != reflect.Map:
+			_ = c.BindEnv(key)
+		}
+	}
+}
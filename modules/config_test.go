@@ -0,0 +1,100 @@
+package modules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewConfig_precedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := "app:\n  port: 4000\n  database:\n    host: file-host\n    user: file-user\n    name: file-name\n"
+	if err = ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(file), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("GRPOC_APP_DATABASE_HOST", "env-host")
+	os.Setenv("GRPOC_APP_DATABASE_USER", "env-user")
+	defer os.Unsetenv("GRPOC_APP_DATABASE_HOST")
+	defer os.Unsetenv("GRPOC_APP_DATABASE_USER")
+
+	c, err := NewConfig(ConfigSource{
+		Path:      dir,
+		Defaults:  map[string]interface{}{"app.port": 3000, "app.health.interval": "10s"},
+		Overrides: ConfigOverrides{ConfigKeyDbUser: "flag-user"},
+	})
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: ConfigKeyDbUser, want: "flag-user"},
+		{key: ConfigKeyDbHost, want: "env-host"},
+		{key: ConfigKeyDbName, want: "file-name"},
+		{key: "app.port", want: "4000"},
+		{key: "app.health.interval", want: "10s"},
+		{key: ConfigKeyLogLevel, want: DefaultLogLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := c.GetString(tt.key); got != tt.want {
+				t.Errorf("GetString(%s) = %s, want %s", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	// values from the environment and the command line are strings, the typed getters convert them
+	os.Setenv("GRPOC_APP_DATABASE_PORT", "3307")
+	defer os.Unsetenv("GRPOC_APP_DATABASE_PORT")
+	if got := c.GetInt(ConfigKeyDbPort); got != 3307 {
+		t.Errorf("GetInt(%s) = %d, want 3307", ConfigKeyDbPort, got)
+	}
+}
+
+func TestNewConfig_missingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := NewConfig(ConfigSource{Path: dir})
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v, want the defaults without a file", err)
+	}
+	if got := c.GetInt(ConfigKeyDbPort); got != 3306 {
+		t.Errorf("GetInt(%s) = %d, want the default", ConfigKeyDbPort, got)
+	}
+	if got := c.GetInt(ConfigKeyAppPort); got != DefaultAppPort {
+		t.Errorf("GetInt(%s) = %d, want the default", ConfigKeyAppPort, got)
+	}
+	if got := c.GetDuration(ConfigKeyShutdownTimeout); got != DefaultShutdownTimeout {
+		t.Errorf("GetDuration(%s) = %s, want the default", ConfigKeyShutdownTimeout, got)
+	}
+}
+
+func TestConfigOverrides_Set(t *testing.T) {
+	o := ConfigOverrides{}
+
+	if err := o.Set("App.Database.Password=s3cr=t"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if o[ConfigKeyDbPassword] != "s3cr=t" {
+		t.Errorf("Set() = %v, want the value after the first =", o)
+	}
+
+	for _, value := range []string{"app.port", "=4000"} {
+		if err := o.Set(value); err == nil {
+			t.Errorf("Set(%s) error = nil, want an error", value)
+		}
+	}
+}
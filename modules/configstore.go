@@ -0,0 +1,225 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	InstConfigStore = "primary_config_store"
+)
+
+// ConfigValidator - Check a config before it is used, a reload failing one keeps the current config.
+// The error is reported as is, it names the config
+type ConfigValidator func(c *viper.Viper) error
+
+// ConfigSubscriber - Apply the keys that changed in a reload, c is the new config
+type ConfigSubscriber func(c *viper.Viper, changed []string)
+
+// subscription - Subscriber and the keys it is interested in, no keys is every key
+type subscription struct {
+	keys []string
+	fn   ConfigSubscriber
+}
+
+// ConfigStore - Holds the current config and swaps in a new one when the file changes or on Reload.
+// Every config it hands out is a snapshot which is never modified, a reload builds a new one
+type ConfigStore struct {
+	source     ConfigSource
+	validators []ConfigValidator
+	current    atomic.Value
+
+	// reloading serializes the reloads, the subscribers apply them in order
+	reloading sync.Mutex
+
+	// mu guards the subscriptions, the logger and the watch, it is not held while the subscribers run
+	mu            sync.Mutex
+	subscriptions []subscription
+	logger        *zap.Logger
+	stopWatch     func()
+}
+
+// NewConfigStore - Read and validate the config of the source
+func NewConfigStore(source ConfigSource, validators ...ConfigValidator) (s *ConfigStore, err error) {
+	s = &ConfigStore{source: source, validators: validators, logger: zap.NewNop()}
+
+	c, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	s.current.Store(c)
+
+	return
+}
+
+// Path - Directory config.yaml is read from
+func (s *ConfigStore) Path() string {
+	return s.source.Path
+}
+
+// Config - The current config snapshot
+func (s *ConfigStore) Config() *viper.Viper {
+	return s.current.Load().(*viper.Viper)
+}
+
+// Subscribe - Call fn after a reload changing one of the keys, a key covers the keys below it
+func (s *ConfigStore) Subscribe(fn ConfigSubscriber, keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions = append(s.subscriptions, subscription{keys: keys, fn: fn})
+}
+
+// Reload - Read the config again and swap it in when it is valid, the subscribers of the changed keys are called.
+// An invalid config is rejected and the current one is kept. The subscribers may read the store and subscribe,
+// they must not reload it
+func (s *ConfigStore) Reload() (changed []string, err error) {
+	s.reloading.Lock()
+	defer s.reloading.Unlock()
+
+	s.mu.Lock()
+	logger := s.logger
+	subscriptions := append([]subscription(nil), s.subscriptions...)
+	s.mu.Unlock()
+
+	c, err := s.load()
+	if err != nil {
+		logger.Error("Config reload rejected, keeping the current config", zap.Error(err))
+		return nil, err
+	}
+
+	if changed = diffConfig(s.Config(), c); len(changed) == 0 {
+		// a write of the same content or the ConfigMap swap of another file
+		logger.Debug("Config reloaded, nothing changed")
+		return
+	}
+
+	s.current.Store(c)
+
+	// values are left out of the logs, the config holds passwords and secrets
+	logger.Info("Config reloaded", zap.Strings("changed", changed))
+
+	applied := make(map[string]bool, len(changed))
+	for _, sub := range subscriptions {
+		keys := matchKeys(sub.keys, changed)
+		if len(keys) == 0 {
+			continue
+		}
+		sub.fn(c, keys)
+		for _, k := range keys {
+			applied[k] = true
+		}
+	}
+
+	var restart []string
+	for _, k := range changed {
+		if !applied[k] {
+			restart = append(restart, k)
+		}
+	}
+	if len(restart) > 0 {
+		logger.Warn("Config keys changed which are applied on the next start", zap.Strings("keys", restart))
+	}
+
+	return
+}
+
+// Watch - Reload when the config file changes, the editors replacing the file and the symlink swaps
+// of mounted ConfigMaps are caught. The other files of its directory do not reload it.
+// Nothing is watched when the config was not read from a file
+func (s *ConfigStore) Watch(watcher *FileWatcher, logger *zap.Logger) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logger = logger
+
+	file := s.Config().ConfigFileUsed()
+	if file == "" || s.stopWatch != nil {
+		return
+	}
+
+	// the diff tells whether the config changed
+	s.stopWatch, err = watcher.Watch("config", func() {
+		_, _ = s.Reload()
+	}, file)
+
+	return
+}
+
+// Close - Stop watching the config file
+func (s *ConfigStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopWatch != nil {
+		s.stopWatch()
+	}
+
+	return nil
+}
+
+// load - Read the config of the source and run the validators on it
+func (s *ConfigStore) load() (c *viper.Viper, err error) {
+	if c, err = NewConfig(s.source); err != nil {
+		return nil, fmt.Errorf("failed to read config-> %v", err)
+	}
+
+	var problems []string
+	for _, validate := range s.validators {
+		if e := validate(c); e != nil {
+			problems = append(problems, e.Error())
+		}
+	}
+	if len(problems) > 0 {
+		// the validators name the config in their errors
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+
+	return
+}
+
+// diffConfig - Sorted keys whose value differs between the configs
+func diffConfig(old *viper.Viper, new *viper.Viper) (changed []string) {
+	keys := make(map[string]bool)
+	for _, k := range old.AllKeys() {
+		keys[k] = true
+	}
+	for _, k := range new.AllKeys() {
+		keys[k] = true
+	}
+
+	for k := range keys {
+		if !reflect.DeepEqual(old.Get(k), new.Get(k)) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+
+	return
+}
+
+// matchKeys - Keys of changed covered by one of the subscribed keys, every key when none is subscribed
+func matchKeys(subscribed []string, changed []string) (keys []string) {
+	if len(subscribed) == 0 {
+		return changed
+	}
+
+	for _, k := range changed {
+		for _, s := range subscribed {
+			if k == s || strings.HasPrefix(k, s+".") {
+				keys = append(keys, k)
+				break
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,187 @@
+package modules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func writeConfig(t *testing.T, dir string, content string) {
+	t.Helper()
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigStore_Reload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeConfig(t, dir, "app:\n  interceptors: [requestid]\n  port: 3000\n  log:\n    level: info\n")
+	s, err := NewConfigStore(ConfigSource{Path: dir}, ValidateAppConfig)
+	if err != nil {
+		t.Fatalf("NewConfigStore() error = %v", err)
+	}
+
+	// the file is watched at the end, the reloads before are the ones of the test
+	core, logs := observer.New(zap.InfoLevel)
+	s.logger = zap.New(core)
+
+	var got []string
+	s.Subscribe(func(c *viper.Viper, changed []string) { got = changed }, "app.log")
+
+	// a rejected reload keeps the current config
+	writeConfig(t, dir, "app:\n  interceptors: [requestid]\n  port: 3000\n  log:\n    level: loud\n")
+	_, err = s.Reload()
+	if err == nil {
+		t.Fatal("Reload() error = nil, want an error for the log level")
+	}
+	if !strings.HasPrefix(err.Error(), "invalid config: ") || strings.Count(err.Error(), "invalid config") != 1 {
+		t.Errorf("Reload() error = %v, want the problems after one invalid config prefix", err)
+	}
+	if level := s.Config().GetString(ConfigKeyLogLevel); level != "info" {
+		t.Errorf("Config() log level = %s after a rejected reload, want info", level)
+	}
+	if got != nil {
+		t.Errorf("subscriber called with %v after a rejected reload", got)
+	}
+
+	old := s.Config()
+	writeConfig(t, dir, "app:\n  interceptors: [requestid]\n  port: 4000\n  log:\n    level: debug\n")
+	changed, err := s.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if want := []string{"app.log.level", "app.port"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("Reload() changed = %v, want %v", changed, want)
+	}
+	if !reflect.DeepEqual(got, []string{"app.log.level"}) {
+		t.Errorf("subscriber called with %v, want the log level only", got)
+	}
+	if old.GetInt("app.port") != 3000 || s.Config().GetInt("app.port") != 4000 {
+		t.Error("Reload() modified the old snapshot or did not swap in the new one")
+	}
+	if n := logs.FilterMessage("Config keys changed which are applied on the next start").Len(); n != 1 {
+		t.Errorf("logged %d warnings for app.port, want 1", n)
+	}
+
+	// the watch reloads on its own
+	watcher := testWatcher(t)
+	defer watcher.Close()
+
+	if err = s.Watch(watcher, s.logger); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer s.Close()
+
+	writeConfig(t, dir, "app:\n  interceptors: [requestid]\n  port: 4000\n  log:\n    level: warn\n")
+	for deadline := time.Now().Add(5 * time.Second); s.Config().GetString(ConfigKeyLogLevel) != "warn"; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not reload the changed file")
+		}
+	}
+}
+
+func TestConfigStore_Reload_subscriberUsesStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeConfig(t, dir, "app:\n  interceptors: [requestid]\n  port: 3000\n")
+	s, err := NewConfigStore(ConfigSource{Path: dir}, ValidateAppConfig)
+	if err != nil {
+		t.Fatalf("NewConfigStore() error = %v", err)
+	}
+
+	// the subscriber runs without the lock of the store, it reads it and subscribes
+	var port int
+	s.Subscribe(func(c *viper.Viper, changed []string) {
+		port = s.Config().GetInt(ConfigKeyAppPort)
+		s.Subscribe(func(c *viper.Viper, changed []string) {}, ConfigKeyAppPort)
+		_ = s.Close()
+	}, ConfigKeyAppPort)
+
+	writeConfig(t, dir, "app:\n  interceptors: [requestid]\n  port: 4000\n")
+	reloaded := make(chan error, 1)
+	go func() {
+		_, err := s.Reload()
+		reloaded <- err
+	}()
+
+	select {
+	case err = <-reloaded:
+		if err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Reload() deadlocked on the subscriber")
+	}
+	if port != 4000 {
+		t.Errorf("subscriber read the port %d, want the new config", port)
+	}
+}
+
+func TestConfigStore_Watch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// layout of a mounted ConfigMap, config.yaml -> ..data/config.yaml and ..data -> the current version
+	version := func(name string, content string) {
+		t.Helper()
+		if err := os.Mkdir(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+		writeConfig(t, filepath.Join(dir, name), content)
+	}
+	version("..v1", "app:\n  interceptors: [requestid]\n  port: 3000\n  log:\n    level: info\n")
+	if err = os.Symlink("..v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Symlink(filepath.Join("..data", "config.yaml"), filepath.Join(dir, "config.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewConfigStore(ConfigSource{Path: dir}, ValidateAppConfig)
+	if err != nil {
+		t.Fatalf("NewConfigStore() error = %v", err)
+	}
+	watcher := testWatcher(t)
+	defer watcher.Close()
+
+	if err = s.Watch(watcher, zap.NewNop()); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer s.Close()
+
+	// the kubelet writes the new version and renames a new symlink over ..data
+	version("..v2", "app:\n  interceptors: [requestid]\n  port: 3000\n  log:\n    level: warn\n")
+	if err = os.Symlink("..v2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); s.Config().GetString(ConfigKeyLogLevel) != "warn"; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not reload after the ..data symlink swap")
+		}
+	}
+}
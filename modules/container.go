@@ -1,15 +1,17 @@
 package modules
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"fmt"
-	"log"
+	"net"
 	"os"
+	"strconv"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/sarulabs/di"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 const (
@@ -17,6 +19,7 @@ const (
 	DefaultConfigPath = "./configs"
 	InstDatabase      = "primary_db"
 	InstAppConfig     = "primary_config"
+	InstContext       = "primary_context"
 
 	ConfigKeyDbHost     = "app.database.host"
 	ConfigKeyDbUser     = "app.database.user"
@@ -25,8 +28,9 @@ const (
 	ConfigKeyDbPort     = "app.database.port"
 )
 
-// InitContainer - Initialize the container and bootstrap the resources
-func InitContainer() (container di.Container, err error) {
+// InitContainer - Initialize the container and bootstrap the resources, the config is read from the source.
+// Cancelling ctx gives up the builds waiting at the start, like the database ping
+func InitContainer(ctx context.Context, source ConfigSource) (container di.Container, err error) {
 	var (
 		builder *di.Builder
 	)
@@ -36,7 +40,23 @@ func InitContainer() (container di.Container, err error) {
 		return
 	}
 
-	if err = InitAppConfig(builder); err != nil {
+	if err = InitContext(builder, ctx); err != nil {
+		return
+	}
+
+	if err = InitAppConfig(builder, source); err != nil {
+		return
+	}
+
+	if err = InitConfig(builder); err != nil {
+		return
+	}
+
+	if err = InitLogger(builder); err != nil {
+		return
+	}
+
+	if err = InitWatcher(builder); err != nil {
 		return
 	}
 
@@ -44,6 +64,26 @@ func InitContainer() (container di.Container, err error) {
 		return
 	}
 
+	if err = InitTLS(builder); err != nil {
+		return
+	}
+
+	if err = InitAuth(builder); err != nil {
+		return
+	}
+
+	if err = InitAuthz(builder); err != nil {
+		return
+	}
+
+	if err = InitMetrics(builder); err != nil {
+		return
+	}
+
+	if err = InitTracing(builder); err != nil {
+		return
+	}
+
 	// build container
 	container = builder.Build()
 
@@ -55,32 +95,49 @@ func InitContainer() (container di.Container, err error) {
 	return
 }
 
-// InitAppConfig - Initialize application config and store in container
-func InitAppConfig(builder *di.Builder) (err error) {
+// InitAppConfig - Initialize the config store and the startup config snapshot and store in container.
+// The snapshot does not change, resources applying reloads subscribe to the store
+func InitAppConfig(builder *di.Builder, source ConfigSource) (err error) {
 
 	err = builder.Add(
 		di.Def{
-			Name:  InstAppConfig,
+			Name:  InstConfigStore,
 			Scope: di.App,
 			Build: func(ctn di.Container) (i interface{}, e error) {
-				path := os.Getenv(ConfigPath)
-				c := viper.New()
-				c.SetConfigName("config")
-				c.SetConfigType("yaml")
-				if path == "" {
-					c.AddConfigPath(DefaultConfigPath) // look for config in the working directory
-					log.Println("Loading configs from default location...")
-				} else {
-					c.AddConfigPath(path)
-					log.Printf("Loading configs from location: %s\n", path)
+				if source.Path == "" {
+					source.Path = os.Getenv(ConfigPath)
 				}
-
-				err = c.ReadInConfig()
-				if err != nil {
-					return
+				if source.Path == "" {
+					source.Path = DefaultConfigPath // look for config in the working directory
 				}
-				i = c
-				return
+
+				return NewConfigStore(source, ValidateAppConfig)
+			},
+			Close: func(obj interface{}) error {
+				return obj.(*ConfigStore).Close()
+			},
+		},
+		di.Def{
+			Name:  InstAppConfig,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				return ctn.Get(InstConfigStore).(*ConfigStore).Config(), nil
+			},
+		})
+
+	return
+}
+
+// InitContext - Store the context of the start in container, the builds waiting on something give up once it ends.
+// It is not for the resources to keep, it usually ends when the app started
+func InitContext(builder *di.Builder, ctx context.Context) (err error) {
+
+	err = builder.Add(
+		di.Def{
+			Name:  InstContext,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				return ctx, nil
 			},
 		})
 
@@ -97,21 +154,39 @@ func InitDatabase(builder *di.Builder) (err error) {
 			Scope: di.App,
 			Build: func(ctn di.Container) (i interface{}, e error) {
 
-				var db *sql.DB
-				// get database configs
-				host := ctn.Get(InstAppConfig).(*viper.Viper).Get(ConfigKeyDbHost).(string)
-				user := ctn.Get(InstAppConfig).(*viper.Viper).Get(ConfigKeyDbUser).(string)
-				pass := ctn.Get(InstAppConfig).(*viper.Viper).Get(ConfigKeyDbPassword).(string)
-				name := ctn.Get(InstAppConfig).(*viper.Viper).Get(ConfigKeyDbName).(string)
-				port := ctn.Get(InstAppConfig).(*viper.Viper).Get(ConfigKeyDbPort).(int)
+				var (
+					db  *sql.DB
+					cfg = ctn.Get(InstConfig).(*AppConfig).Database
+				)
 
-				dbSource := user + ":" + pass + "@tcp(" + host + ":" + fmt.Sprint(port) + ")/" + name
-				db, e = sql.Open("mysql", dbSource)
+				db, e = sql.Open("mysql", cfg.DSN())
 
 				if e != nil {
 					return
 				}
 
+				cfg.ApplyPool(db)
+
+				// the pool limits apply on a reload, the connection settings on the next start
+				ctn.Get(InstConfigStore).(*ConfigStore).Subscribe(func(c *viper.Viper, changed []string) {
+					if cfg, err := LoadAppConfig(c); err == nil {
+						cfg.Database.ApplyPool(db)
+					}
+				}, ConfigKeyDbMaxOpenConns, ConfigKeyDbMaxIdleConns, ConfigKeyDbConnMaxLifetime, ConfigKeyDbConnMaxIdleTime)
+
+				// the retries give up when the start is cancelled, a container without a context waits them out
+				ctx := context.Background()
+				if c, err := ctn.SafeGet(InstContext); err == nil {
+					ctx = c.(context.Context)
+				}
+
+				// sql.Open does not connect, ping so a wrong address or password fails the start and not the first call
+				addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+				if e = PingWithRetry(ctx, db, addr, cfg.Ping, ctn.Get(InstLogger).(*zap.Logger)); e != nil {
+					db.Close()
+					return
+				}
+
 				return db, nil
 			},
 			Close: func(obj interface{}) error {
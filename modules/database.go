@@ -0,0 +1,260 @@
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
+)
+
+const (
+	ConfigKeyDbMaxOpenConns    = "app.database.maxopenconns"
+	ConfigKeyDbMaxIdleConns    = "app.database.maxidleconns"
+	ConfigKeyDbConnMaxLifetime = "app.database.connmaxlifetime"
+	ConfigKeyDbConnMaxIdleTime = "app.database.connmaxidletime"
+	ConfigKeyDbDialTimeout     = "app.database.dialtimeout"
+	ConfigKeyDbReadTimeout     = "app.database.readtimeout"
+	ConfigKeyDbWriteTimeout    = "app.database.writetimeout"
+	ConfigKeyDbCharset         = "app.database.charset"
+	ConfigKeyDbCollation       = "app.database.collation"
+	ConfigKeyDbLoc             = "app.database.loc"
+	ConfigKeyDbParseTime       = "app.database.parsetime"
+	ConfigKeyDbTLS             = "app.database.tls"
+
+	ConfigKeyDbPingAttempts   = "app.database.ping.attempts"
+	ConfigKeyDbPingBackoff    = "app.database.ping.backoff"
+	ConfigKeyDbPingMaxBackoff = "app.database.ping.maxbackoff"
+	ConfigKeyDbPingTimeout    = "app.database.ping.timeout"
+
+	// DefaultDbMaxIdleConns is the idle pool size of database/sql, used when none is configured
+	DefaultDbMaxIdleConns = 2
+
+	// Startup ping defaults, used for the settings which are not configured
+	DefaultDbPingAttempts   = 5
+	DefaultDbPingBackoff    = 500 * time.Millisecond
+	DefaultDbPingMaxBackoff = 10 * time.Second
+	DefaultDbPingTimeout    = 5 * time.Second
+)
+
+// dbTLSModes are the TLS settings of the driver which need no registered config
+var dbTLSModes = map[string]bool{"": true, "false": true, "true": true, "skip-verify": true}
+
+var (
+	// charsetPattern charset name, or a comma separated list of them tried in order
+	charsetPattern = regexp.MustCompile(`^[A-Za-z0-9_]+(,[A-Za-z0-9_]+)*$`)
+	// collationPattern collation name
+	collationPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
+
+// DatabaseConfig - Connection and pool settings of the primary database
+type DatabaseConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+
+	MaxOpenConns    int           // 0 is unlimited
+	MaxIdleConns    int           // 0 keeps no idle connection, negative values are invalid
+	ConnMaxLifetime time.Duration // 0 keeps connections forever
+	ConnMaxIdleTime time.Duration // 0 keeps idle connections forever
+
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	Charset   string
+	Collation string
+	Loc       string
+	ParseTime bool
+	TLS       string // false, true or skip-verify
+}
+
+// problems - Every invalid setting
+func (cfg DatabaseConfig) problems() (problems []string) {
+
+	invalid := func(key string, format string, args ...interface{}) {
+		problems = append(problems, key+" "+fmt.Sprintf(format, args...))
+	}
+
+	if cfg.Host == "" {
+		invalid(ConfigKeyDbHost, "is required")
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		invalid(ConfigKeyDbPort, "should be between 1 and 65535, got %d", cfg.Port)
+	}
+	if cfg.User == "" {
+		invalid(ConfigKeyDbUser, "is required")
+	}
+	if cfg.Name == "" {
+		invalid(ConfigKeyDbName, "is required")
+	}
+
+	if cfg.MaxOpenConns < 0 {
+		invalid(ConfigKeyDbMaxOpenConns, "should be 0 for unlimited or more, got %d", cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns < 0 {
+		invalid(ConfigKeyDbMaxIdleConns, "should be 0 or more, got %d", cfg.MaxIdleConns)
+	}
+	if cfg.MaxOpenConns > 0 && cfg.MaxIdleConns > cfg.MaxOpenConns {
+		invalid(ConfigKeyDbMaxIdleConns, "%d should not exceed %s %d", cfg.MaxIdleConns, ConfigKeyDbMaxOpenConns, cfg.MaxOpenConns)
+	}
+
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{ConfigKeyDbConnMaxLifetime, cfg.ConnMaxLifetime},
+		{ConfigKeyDbConnMaxIdleTime, cfg.ConnMaxIdleTime},
+		{ConfigKeyDbDialTimeout, cfg.DialTimeout},
+		{ConfigKeyDbReadTimeout, cfg.ReadTimeout},
+		{ConfigKeyDbWriteTimeout, cfg.WriteTimeout},
+	} {
+		if d.value < 0 {
+			invalid(d.key, "should not be negative, got %s", d.value)
+		}
+	}
+
+	if cfg.Charset != "" && !charsetPattern.MatchString(cfg.Charset) {
+		invalid(ConfigKeyDbCharset, "'%s' is not a charset name", cfg.Charset)
+	}
+	if cfg.Collation != "" && !collationPattern.MatchString(cfg.Collation) {
+		invalid(ConfigKeyDbCollation, "'%s' is not a collation name", cfg.Collation)
+	} else if cfg.Collation != "" && cfg.Charset != "" && !collationOf(cfg.Collation, cfg.Charset) {
+		// the collation sets the charset, a different one configured next to it would be ignored
+		invalid(ConfigKeyDbCollation, "'%s' is not a collation of %s '%s'", cfg.Collation, ConfigKeyDbCharset, cfg.Charset)
+	}
+	if _, err := time.LoadLocation(cfg.Loc); cfg.Loc != "" && err != nil {
+		invalid(ConfigKeyDbLoc, "'%s' is not a time zone, e.g. UTC or Local", cfg.Loc)
+	}
+	if !dbTLSModes[cfg.TLS] {
+		invalid(ConfigKeyDbTLS, "should be false, true or skip-verify, got '%s'", cfg.TLS)
+	}
+
+	return
+}
+
+// DSN - Data source name of the settings. clientFoundRows is always on so UPDATE reports matched rows
+// and an unchanged row is not taken as missing.
+// The charset is left out when a collation is set, the driver would run SET NAMES with the charset
+// after the handshake and reset the session to the default collation of the charset
+func (cfg DatabaseConfig) DSN() string {
+	dsn := mysql.NewConfig()
+	dsn.User = cfg.User
+	dsn.Passwd = cfg.Password
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	dsn.DBName = cfg.Name
+	dsn.Timeout = cfg.DialTimeout
+	dsn.ReadTimeout = cfg.ReadTimeout
+	dsn.WriteTimeout = cfg.WriteTimeout
+	dsn.ParseTime = cfg.ParseTime
+	dsn.TLSConfig = cfg.TLS
+	dsn.ClientFoundRows = true
+
+	if cfg.Collation != "" {
+		dsn.Collation = cfg.Collation
+	} else if cfg.Charset != "" {
+		dsn.Params = map[string]string{"charset": cfg.Charset}
+	}
+	if cfg.Loc != "" {
+		dsn.Loc, _ = time.LoadLocation(cfg.Loc)
+	}
+
+	return dsn.FormatDSN()
+}
+
+// collationOf - Whether the collation belongs to the first charset of the list, collation names start with their charset
+func collationOf(collation string, charsets string) bool {
+	charset := strings.Split(charsets, ",")[0]
+
+	return collation == charset || strings.HasPrefix(collation, charset+"_")
+}
+
+// ApplyPool - Set the pool limits on the database
+func (cfg DatabaseConfig) ApplyPool(db *sql.DB) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+// PingPolicy - How the database is pinged at startup. Attempts 0 skips the ping,
+// the wait between attempts starts at Backoff and doubles up to MaxBackoff
+type PingPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Timeout    time.Duration // of every attempt
+}
+
+// pinger is implemented by *sql.DB
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Validate - Check the settings, 0 attempts skip the ping
+func (p PingPolicy) Validate() (err error) {
+	switch {
+	case p.Attempts < 0:
+		err = fmt.Errorf("%s should be 0 to skip the ping or more, got %d", ConfigKeyDbPingAttempts, p.Attempts)
+	case p.Backoff < 0 || p.MaxBackoff < p.Backoff:
+		err = fmt.Errorf("%s %s should be between 0 and %s %s", ConfigKeyDbPingBackoff, p.Backoff, ConfigKeyDbPingMaxBackoff, p.MaxBackoff)
+	case p.Timeout <= 0:
+		err = fmt.Errorf("%s should be positive, got %s", ConfigKeyDbPingTimeout, p.Timeout)
+	}
+
+	return
+}
+
+// PingWithRetry - Ping the database until it answers, logging every failed attempt.
+// The error names the address and the last failure once the attempts are used up
+func PingWithRetry(ctx context.Context, db pinger, addr string, p PingPolicy, logger *zap.Logger) (err error) {
+	backoff := p.Backoff
+
+	for attempt := 1; attempt <= p.Attempts; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, p.Timeout)
+		err = db.PingContext(pingCtx)
+		cancel()
+
+		if err == nil {
+			logger.Info("Connected to the database", zap.String("addr", addr), zap.Int("attempt", attempt))
+			return nil
+		}
+
+		if attempt == p.Attempts {
+			break
+		}
+
+		logger.Warn("Database not reachable, retrying",
+			zap.String("addr", addr),
+			zap.Int("attempt", attempt),
+			zap.Int("attempts", p.Attempts),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up connecting to the database at %s-> %v", addr, ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("database at %s not reachable after %d attempts-> %v", addr, p.Attempts, err)
+	}
+
+	return
+}
@@ -0,0 +1,202 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDatabaseConfig_DSN(t *testing.T) {
+	cfg := DatabaseConfig{
+		Host:         "db.internal",
+		Port:         3307,
+		User:         "app",
+		Password:     "p@ss:word/",
+		Name:         "todos",
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  30 * time.Second,
+		Charset:      "utf8mb4",
+		Collation:    "utf8mb4_unicode_ci",
+		Loc:          "Europe/Paris",
+		ParseTime:    true,
+		TLS:          "skip-verify",
+		MaxOpenConns: 10,
+	}
+
+	parsed, err := mysql.ParseDSN(cfg.DSN())
+	if err != nil {
+		t.Fatalf("DSN() = %s is not valid-> %v", cfg.DSN(), err)
+	}
+
+	if parsed.User != "app" || parsed.Passwd != "p@ss:word/" || parsed.Addr != "db.internal:3307" || parsed.DBName != "todos" {
+		t.Errorf("DSN() connection = %+v", parsed)
+	}
+	if !parsed.ClientFoundRows || !parsed.ParseTime || parsed.TLSConfig != "skip-verify" {
+		t.Errorf("DSN() flags = %+v", parsed)
+	}
+	if parsed.Timeout != 5*time.Second || parsed.ReadTimeout != 30*time.Second || parsed.Loc.String() != "Europe/Paris" {
+		t.Errorf("DSN() timeouts and location = %+v", parsed)
+	}
+	if _, ok := parsed.Params["charset"]; ok || parsed.Collation != "utf8mb4_unicode_ci" {
+		t.Errorf("DSN() charset = %v %s, want the collation only", parsed.Params, parsed.Collation)
+	}
+}
+
+func TestDatabaseConfig_DSN_charset(t *testing.T) {
+	tests := []struct {
+		name          string
+		charset       string
+		collation     string
+		wantParams    map[string]string
+		wantCollation string
+	}{
+		// SET NAMES <charset> after the handshake would reset the collation
+		{name: "Collation", charset: "utf8mb4", collation: "utf8mb4_unicode_ci", wantCollation: "utf8mb4_unicode_ci"},
+		{name: "Charset", charset: "utf8mb4,utf8", wantParams: map[string]string{"charset": "utf8mb4,utf8"}, wantCollation: mysql.NewConfig().Collation},
+		{name: "Neither", wantCollation: mysql.NewConfig().Collation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DatabaseConfig{Host: "localhost", Port: 3306, User: "root", Name: "grpc_poc", Charset: tt.charset, Collation: tt.collation}
+
+			parsed, err := mysql.ParseDSN(cfg.DSN())
+			if err != nil {
+				t.Fatalf("DSN() = %s is not valid-> %v", cfg.DSN(), err)
+			}
+
+			if !reflect.DeepEqual(parsed.Params, tt.wantParams) || parsed.Collation != tt.wantCollation {
+				t.Errorf("DSN() params = %v, collation = %s, want %v, %s", parsed.Params, parsed.Collation, tt.wantParams, tt.wantCollation)
+			}
+		})
+	}
+}
+
+func TestLoadAppConfig_database(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		wantErrs []string
+	}{
+		{
+			name: "Valid with default idle connections",
+		},
+		{
+			name: "Every problem reported",
+			settings: map[string]interface{}{
+				ConfigKeyDbHost:         "",
+				ConfigKeyDbPort:         70000,
+				ConfigKeyDbMaxOpenConns: 5,
+				ConfigKeyDbMaxIdleConns: 10,
+				ConfigKeyDbReadTimeout:  "-1s",
+				ConfigKeyDbLoc:          "Mars/Olympus",
+				ConfigKeyDbTLS:          "maybe",
+				ConfigKeyDbCharset:      "utf8; DROP",
+			},
+			wantErrs: []string{ConfigKeyDbHost, ConfigKeyDbPort, ConfigKeyDbMaxIdleConns, ConfigKeyDbReadTimeout, ConfigKeyDbLoc, ConfigKeyDbTLS, ConfigKeyDbCharset},
+		},
+		{
+			name: "Collation of another charset",
+			settings: map[string]interface{}{
+				ConfigKeyDbCharset:   "latin1",
+				ConfigKeyDbCollation: "utf8mb4_unicode_ci",
+			},
+			wantErrs: []string{ConfigKeyDbCollation},
+		},
+		{
+			name:     "Backoff above the maximum",
+			settings: map[string]interface{}{ConfigKeyDbPingBackoff: "1m"},
+			wantErrs: []string{ConfigKeyDbPingBackoff},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := viper.New()
+			for k, v := range ConfigDefaults {
+				c.SetDefault(k, v)
+			}
+			c.Set(ConfigKeyInterceptors, InterceptorRequestID)
+			for k, v := range tt.settings {
+				c.Set(k, v)
+			}
+
+			cfg, err := LoadAppConfig(c)
+			if (err != nil) != (len(tt.wantErrs) > 0) {
+				t.Fatalf("LoadAppConfig() error = %v, want errors for %v", err, tt.wantErrs)
+			}
+			for _, key := range tt.wantErrs {
+				if !strings.Contains(err.Error(), key) {
+					t.Errorf("LoadAppConfig() error = %v, want a problem with %s", err, key)
+				}
+			}
+			if err != nil {
+				return
+			}
+
+			if cfg.Database.MaxIdleConns != DefaultDbMaxIdleConns {
+				t.Errorf("LoadAppConfig() MaxIdleConns = %d, want %d", cfg.Database.MaxIdleConns, DefaultDbMaxIdleConns)
+			}
+			want := PingPolicy{Attempts: DefaultDbPingAttempts, Backoff: DefaultDbPingBackoff, MaxBackoff: DefaultDbPingMaxBackoff, Timeout: DefaultDbPingTimeout}
+			if cfg.Database.Ping != want {
+				t.Errorf("LoadAppConfig() Ping = %+v, want the defaults %+v", cfg.Database.Ping, want)
+			}
+		})
+	}
+}
+
+// flakyDB fails the first pings
+type flakyDB struct {
+	failures int
+	pings    int
+}
+
+func (db *flakyDB) PingContext(ctx context.Context) error {
+	db.pings++
+	if db.pings <= db.failures {
+		return errors.New("connection refused")
+	}
+
+	return nil
+}
+
+func TestPingWithRetry(t *testing.T) {
+	policy := PingPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Timeout: time.Second}
+
+	tests := []struct {
+		name      string
+		failures  int
+		wantPings int
+		wantErr   bool
+	}{
+		{name: "First attempt", failures: 0, wantPings: 1},
+		{name: "After retries", failures: 2, wantPings: 3},
+		{name: "Attempts used up", failures: 3, wantPings: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			db := &flakyDB{failures: tt.failures}
+
+			err := PingWithRetry(context.Background(), db, "db:3306", policy, zap.New(core))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PingWithRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "db:3306") {
+				t.Errorf("PingWithRetry() error = %v, want the address", err)
+			}
+			if db.pings != tt.wantPings {
+				t.Errorf("PingWithRetry() pinged %d times, want %d", db.pings, tt.wantPings)
+			}
+			if n := logs.FilterMessage("Database not reachable, retrying").Len(); n != tt.wantPings-1 {
+				t.Errorf("PingWithRetry() logged %d retries, want %d", n, tt.wantPings-1)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package modules
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MetadataKeyResponseTime is the trailer the timing interceptor reports the handling time in
+	MetadataKeyResponseTime = "x-response-time"
+)
+
+// PanicsTotal - Number of panics recovered from, by method
+var PanicsTotal = expvar.NewMap("grpc_panics_total")
+
+// requestIDKey is the context key of the request id
+type requestIDKey struct{}
+
+// WithRequestID - Store the request id in the context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID - Get the request id of the call, the one stored in the context or else the one sent by the caller.
+// The result is empty when there is neither
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(MetadataKeyRequestID); len(ids) > 0 {
+			return ids[0]
+		}
+	}
+
+	return ""
+}
+
+// UnaryRequestIDInterceptor - Make sure every unary call has a request id and echo it in the response header
+func UnaryRequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := requestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataKeyRequestID, id))
+
+		return handler(WithRequestID(ctx, id), req)
+	}
+}
+
+// StreamRequestIDInterceptor - Make sure every stream has a request id and echo it in the response header
+func StreamRequestIDInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id := requestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(MetadataKeyRequestID, id))
+
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: WithRequestID(ss.Context(), id)})
+	}
+}
+
+// UnaryRecoveryInterceptor - Turn a panic of a unary handler into a codes.Internal error, the server keeps serving.
+// The panic and its stack are logged, the caller only gets a generic message with the request id
+func UnaryRecoveryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ctx, logger, info.FullMethod, p)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor - Turn a panic of a stream handler into a codes.Internal error, the server keeps serving.
+// The panic and its stack are logged, the caller only gets a generic message with the request id
+func StreamRecoveryInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ss.Context(), logger, info.FullMethod, p)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// recovered - Log and count the panic of a handler and build the status returned instead
+func recovered(ctx context.Context, logger *zap.Logger, method string, p interface{}) error {
+	PanicsTotal.Add(method, 1)
+
+	Logger(ctx, logger).Error("Recovered from panic",
+		zap.String("method", method),
+		zap.String("panic", fmt.Sprint(p)),
+		zap.ByteString("stack", debug.Stack()),
+	)
+
+	if id := RequestID(ctx); id != "" {
+		return status.Errorf(codes.Internal, "internal server error, request id %s", id)
+	}
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// UnaryTimingInterceptor - Report the handling time of every unary call in the response trailer
+// and warn about calls slower than the threshold, a threshold of 0 disables the warning
+func UnaryTimingInterceptor(logger *zap.Logger, slow time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		elapsed := time.Since(start)
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(MetadataKeyResponseTime, elapsed.String()))
+		if slow > 0 && elapsed > slow {
+			Logger(ctx, logger).Warn("Slow call", zap.String("method", info.FullMethod), zap.Duration("duration", elapsed))
+		}
+
+		return resp, err
+	}
+}
+
+// StreamTimingInterceptor - Report the duration of every stream in the response trailer
+// and warn about streams longer than the threshold, a threshold of 0 disables the warning
+func StreamTimingInterceptor(logger *zap.Logger, slow time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+
+		elapsed := time.Since(start)
+		ss.SetTrailer(metadata.Pairs(MetadataKeyResponseTime, elapsed.String()))
+		if slow > 0 && elapsed > slow {
+			Logger(ss.Context(), logger).Warn("Slow stream", zap.String("method", info.FullMethod), zap.Duration("duration", elapsed))
+		}
+
+		return err
+	}
+}
+
+// wrappedStream overrides the context of the wrapped server stream
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context - Context of the stream as seen by the handler
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,79 @@
+package modules
+
+import (
+	"context"
+	"expvar"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testStream is a server stream carrying only a context
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestUnaryRecoveryInterceptor(t *testing.T) {
+	core, logs := observer.New(zapcore.ErrorLevel)
+	method := "/todo.ToDoService/Create"
+	before := panics(method)
+
+	interceptor := UnaryRecoveryInterceptor(zap.New(core))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		var todo *struct{ Title string }
+		return todo.Title, nil
+	}
+
+	ctx := WithRequestID(context.Background(), "abc")
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+	if got := status.Code(err); got != codes.Internal {
+		t.Fatalf("UnaryRecoveryInterceptor() code = %v, want %v", got, codes.Internal)
+	}
+	if msg := status.Convert(err).Message(); strings.Contains(msg, "nil pointer") || !strings.Contains(msg, "abc") {
+		t.Errorf("UnaryRecoveryInterceptor() message = %q, want a sanitized message with the request id", msg)
+	}
+	if got := panics(method); got != before+1 {
+		t.Errorf("UnaryRecoveryInterceptor() panics = %d, want %d", got, before+1)
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].ContextMap()["method"] != method || entries[0].ContextMap()["stack"] == "" {
+		t.Errorf("UnaryRecoveryInterceptor() logged %v, want one entry with method and stack", entries)
+	}
+}
+
+func TestStreamRecoveryInterceptor(t *testing.T) {
+	interceptor := StreamRecoveryInterceptor(zap.NewNop())
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		panic("boom")
+	}
+
+	err := interceptor(nil, &testStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/todo.ToDoService/Watch"}, handler)
+	if got := status.Code(err); got != codes.Internal {
+		t.Fatalf("StreamRecoveryInterceptor() code = %v, want %v", got, codes.Internal)
+	}
+	if msg := status.Convert(err).Message(); strings.Contains(msg, "boom") {
+		t.Errorf("StreamRecoveryInterceptor() message = %q, leaks the panic value", msg)
+	}
+}
+
+// panics - Number of panics recovered for the method so far
+func panics(method string) int64 {
+	if v, ok := PanicsTotal.Get(method).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
@@ -0,0 +1,264 @@
+package modules
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	JWTAlgHS256 = "HS256"
+	JWTAlgRS256 = "RS256"
+)
+
+// jwk - Key of a JWKS file, only the members of the supported key types
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// jwtHeader - Header of a JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtClaims - Claims of a JWT the provider checks or maps to the principal
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Tenant    string          `json:"tenant"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+	Roles     []string        `json:"roles"`
+	Scope     string          `json:"scope"`
+}
+
+// JWTProvider - Authenticates bearer JWTs signed with HS256 or RS256 by the keys of a local JWKS file
+type JWTProvider struct {
+	// keys by kid, []byte for HS256 and *rsa.PublicKey for RS256
+	keys     map[string]interface{}
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// NewJWTProvider - Provider of the keys in the JWKS file. Tokens must have an exp claim,
+// and an aud claim with the audience when it is not empty. The leeway absorbs clock skew
+func NewJWTProvider(jwksFile string, audience string, leeway time.Duration) (*JWTProvider, error) {
+	data, err := ioutil.ReadFile(jwksFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWKS-> %v", err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTProvider{keys: keys, audience: audience, leeway: leeway, now: time.Now}, nil
+}
+
+// Authenticate - Principal of the bearer token of the call
+func (p *JWTProvider) Authenticate(ctx context.Context, md metadata.MD) (*Principal, error) {
+	values := md.Get(MetadataKeyAuthorization)
+	if len(values) == 0 || !strings.HasPrefix(strings.ToLower(values[0]), "bearer ") {
+		return nil, ErrNoCredentials
+	}
+
+	claims, err := p.verify(strings.TrimSpace(values[0][len("bearer "):]))
+	if err != nil {
+		return nil, err
+	}
+
+	tenant := claims.Tenant
+	if tenant == "" {
+		tenant = claims.Subject
+	}
+
+	return &Principal{
+		Subject:  claims.Subject,
+		Tenant:   tenant,
+		Roles:    claims.Roles,
+		Scopes:   strings.Fields(claims.Scope),
+		Provider: "jwt",
+	}, nil
+}
+
+// verify - Check the signature and the registered claims of the token
+func (p *JWTProvider) verify(token string) (claims jwtClaims, err error) {
+	var header jwtHeader
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, errors.New("malformed token")
+	}
+
+	if err = decodeSegment(parts[0], &header); err != nil {
+		return
+	}
+
+	key, err := p.key(header.Kid)
+	if err != nil {
+		return
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, errors.New("malformed token signature")
+	}
+
+	// the key type decides the algorithm, so a token cannot pick HS256 with the RSA public key as secret
+	signed := []byte(parts[0] + "." + parts[1])
+	switch k := key.(type) {
+	case []byte:
+		if header.Alg != JWTAlgHS256 {
+			return claims, fmt.Errorf("algorithm '%s' does not match the key", header.Alg)
+		}
+		mac := hmac.New(sha256.New, k)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return claims, errors.New("invalid token signature")
+		}
+	case *rsa.PublicKey:
+		if header.Alg != JWTAlgRS256 {
+			return claims, fmt.Errorf("algorithm '%s' does not match the key", header.Alg)
+		}
+		sum := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], signature) != nil {
+			return claims, errors.New("invalid token signature")
+		}
+	}
+
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return
+	}
+
+	err = p.checkClaims(claims)
+
+	return
+}
+
+// checkClaims - Check the subject, expiry, not before and audience of the token
+func (p *JWTProvider) checkClaims(claims jwtClaims) error {
+	now := p.now()
+
+	if claims.Subject == "" {
+		return errors.New("token has no subject")
+	}
+
+	if claims.ExpiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+	if now.After(time.Unix(*claims.ExpiresAt, 0).Add(p.leeway)) {
+		return errors.New("token is expired")
+	}
+
+	if claims.NotBefore != nil && now.Before(time.Unix(*claims.NotBefore, 0).Add(-p.leeway)) {
+		return errors.New("token is not valid yet")
+	}
+
+	if p.audience == "" {
+		return nil
+	}
+
+	// aud is either one string or an array of them
+	var audiences []string
+	var single string
+	if json.Unmarshal(claims.Audience, &single) == nil {
+		audiences = []string{single}
+	} else if json.Unmarshal(claims.Audience, &audiences) != nil {
+		return errors.New("token has no audience")
+	}
+
+	for _, aud := range audiences {
+		if aud == p.audience {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("token audience does not include '%s'", p.audience)
+}
+
+// key - Key of the kid, the only key of the set when the token has no kid
+func (p *JWTProvider) key(kid string) (interface{}, error) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, nil
+		}
+	}
+
+	k, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id '%s'", kid)
+	}
+
+	return k, nil
+}
+
+// parseJWKS - Keys of a JWKS document by kid, oct keys for HS256 and RSA keys for RS256
+func parseJWKS(data []byte) (map[string]interface{}, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS-> %v", err)
+	}
+	if len(set.Keys) == 0 {
+		return nil, errors.New("JWKS has no keys")
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		switch k.Kty {
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("invalid oct key '%s' in JWKS", k.Kid)
+			}
+			keys[k.Kid] = secret
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+				return nil, fmt.Errorf("invalid RSA key '%s' in JWKS", k.Kid)
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		default:
+			return nil, fmt.Errorf("unsupported key type '%s' in JWKS", k.Kty)
+		}
+	}
+
+	return keys, nil
+}
+
+// decodeSegment - Decode a base64url JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("malformed token")
+	}
+
+	if err = json.Unmarshal(data, v); err != nil {
+		return errors.New("malformed token")
+	}
+
+	return nil
+}
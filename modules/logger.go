@@ -1 +1,218 @@
 package modules
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/sarulabs/di"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"grpoc/modules/trace"
+)
+
+const (
+	InstLogger = "primary_logger"
+
+	ConfigKeyLogLevel    = "app.log.level"
+	ConfigKeyLogEncoding = "app.log.encoding"
+
+	// LogEncodingJSON and LogEncodingConsole are the supported log encodings
+	LogEncodingJSON    = "json"
+	LogEncodingConsole = "console"
+
+	// DefaultLogLevel is used when no level is configured
+	DefaultLogLevel = "info"
+
+	// MetadataKeyRequestID is the metadata key the caller can set its request id in
+	MetadataKeyRequestID = "x-request-id"
+)
+
+// loggerKey is the context key of the request scoped logger
+type loggerKey struct{}
+
+// InitLogger - Initialize the leveled structured logger and store in container, the level changes on a config reload
+func InitLogger(builder *di.Builder) (err error) {
+
+	err = builder.Add(
+		di.Def{
+			Name:  InstLogger,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				var (
+					c   interface{}
+					lvl zapcore.Level
+				)
+
+				// the logger is built first, an invalid config is reported as the error of its build
+				if c, e = ctn.SafeGet(InstConfig); e != nil {
+					return
+				}
+				cfg := c.(*AppConfig).Log
+
+				if lvl, e = ParseLogLevel(cfg.Level); e != nil {
+					return
+				}
+
+				level := zap.NewAtomicLevelAt(lvl)
+				if i, e = newLogger(level, cfg.Encoding); e != nil {
+					return
+				}
+
+				ctn.Get(InstConfigStore).(*ConfigStore).Subscribe(func(c *viper.Viper, changed []string) {
+					// the reloaded config is validated, the level parses
+					if cfg, err := LoadAppConfig(c); err == nil {
+						lvl, _ := ParseLogLevel(cfg.Log.Level)
+						level.SetLevel(lvl)
+					}
+				}, ConfigKeyLogLevel)
+
+				return
+			},
+			Close: func(obj interface{}) error {
+				// stdout and stderr can not be synced on every platform, there is nothing to report then
+				_ = obj.(*zap.Logger).Sync()
+				return nil
+			},
+		})
+
+	return
+}
+
+// NewLogger - Create a logger writing to stderr with the given level and encoding, empty values use the defaults
+func NewLogger(level string, encoding string) (logger *zap.Logger, err error) {
+	lvl, err := ParseLogLevel(level)
+	if err != nil {
+		return
+	}
+
+	return newLogger(zap.NewAtomicLevelAt(lvl), encoding)
+}
+
+// ParseLogLevel - Parse a level name, empty is the default level
+func ParseLogLevel(level string) (lvl zapcore.Level, err error) {
+	if level == "" {
+		level = DefaultLogLevel
+	}
+
+	if err = lvl.UnmarshalText([]byte(level)); err != nil {
+		err = fmt.Errorf("invalid %s '%s'", ConfigKeyLogLevel, level)
+	}
+
+	return
+}
+
+// newLogger - Create a logger writing to stderr at the level, which can be changed while the logger is used
+func newLogger(level zap.AtomicLevel, encoding string) (logger *zap.Logger, err error) {
+	var cfg zap.Config
+
+	switch encoding {
+	case "", LogEncodingJSON:
+		cfg = zap.NewProductionConfig()
+	case LogEncodingConsole:
+		cfg = zap.NewDevelopmentConfig()
+	default:
+		err = fmt.Errorf("invalid %s '%s', should be %s or %s", ConfigKeyLogEncoding, encoding, LogEncodingJSON, LogEncodingConsole)
+		return
+	}
+
+	cfg.Level = level
+	cfg.EncoderConfig.TimeKey = "time"
+	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	return cfg.Build()
+}
+
+// WithLogger - Store the logger in the context
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger - Get the request scoped logger from the context, or the fallback when there is none
+func Logger(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+
+	return fallback
+}
+
+// UnaryLoggerInterceptor - Attach a logger carrying the method, peer and request id to the context of every unary call
+// and log the outcome of the call
+func UnaryLoggerInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		l := requestLogger(ctx, logger, info.FullMethod)
+
+		start := time.Now()
+		resp, err := handler(WithLogger(ctx, l), req)
+		logCall(l, "Finished call", start, err)
+
+		return resp, err
+	}
+}
+
+// StreamLoggerInterceptor - Attach a logger carrying the method, peer and request id to the context of every stream
+// and log the outcome of the stream
+func StreamLoggerInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		l := requestLogger(ss.Context(), logger, info.FullMethod)
+
+		start := time.Now()
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: WithLogger(ss.Context(), l)})
+		logCall(l, "Finished stream", start, err)
+
+		return err
+	}
+}
+
+// logCall - Log the status code and duration of a call, failed calls are logged as errors
+func logCall(logger *zap.Logger, msg string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []zap.Field{zap.String("code", code.String()), zap.Duration("duration", time.Since(start))}
+
+	switch code {
+	case codes.OK:
+		logger.Info(msg, fields...)
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		logger.Error(msg, append(fields, zap.Error(err))...)
+	default:
+		logger.Warn(msg, append(fields, zap.Error(err))...)
+	}
+}
+
+// requestLogger - Derive the logger of a single call
+func requestLogger(ctx context.Context, logger *zap.Logger, method string) *zap.Logger {
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("request_id", requestID(ctx)),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		fields = append(fields, zap.String("peer", p.Addr.String()))
+	}
+
+	if span := trace.FromContext(ctx); span != nil {
+		fields = append(fields, zap.String("trace_id", span.SpanContext().TraceID.String()))
+	}
+
+	return logger.With(fields...)
+}
+
+// requestID - The request id of the call, or a new one
+func requestID(ctx context.Context) string {
+	if id := RequestID(ctx); id != "" {
+		return id
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
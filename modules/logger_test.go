@@ -0,0 +1,41 @@
+package modules
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    string
+		encoding string
+		wantErr  bool
+	}{
+		{name: "Defaults"},
+		{name: "Console debug", level: "debug", encoding: LogEncodingConsole},
+		{name: "JSON error", level: "error", encoding: LogEncodingJSON},
+		{name: "Unknown level", level: "loud", wantErr: true},
+		{name: "Unknown encoding", encoding: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLogger(tt.level, tt.encoding); (err != nil) != tt.wantErr {
+				t.Errorf("NewLogger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_requestID(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKeyRequestID, "abc"))
+	if got := requestID(ctx); got != "abc" {
+		t.Errorf("requestID() = %v, want %v", got, "abc")
+	}
+
+	if got := requestID(context.Background()); len(got) != 16 {
+		t.Errorf("requestID() = %v, want a generated id", got)
+	}
+}
@@ -0,0 +1,210 @@
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sarulabs/di"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	InstMetrics = "primary_metrics"
+
+	ConfigKeyMetricsEnabled = "app.metrics.enabled"
+	ConfigKeyMetricsPort    = "app.metrics.port"
+	ConfigKeyMetricsPath    = "app.metrics.path"
+
+	// DefaultMetricsPath is used when no path is configured
+	DefaultMetricsPath = "/metrics"
+
+	// metricsShutdownTimeout bounds how long Close waits for running scrapes
+	metricsShutdownTimeout = 5 * time.Second
+)
+
+// Metrics - Prometheus metrics of the RPCs, the database pool and the runtime, served over HTTP
+type Metrics struct {
+	registry *prometheus.Registry
+	handled  *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	server   *http.Server
+}
+
+// InitMetrics - Initialize the metrics of the primary database and store in container
+func InitMetrics(builder *di.Builder) (err error) {
+
+	err = builder.Add(
+		di.Def{
+			Name:  InstMetrics,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				return NewMetrics(ctn.Get(InstDatabase).(*sql.DB), ctn.Get(InstConfig).(*AppConfig).Metrics.Path)
+			},
+			Close: func(obj interface{}) error {
+				return obj.(*Metrics).Close()
+			},
+		})
+
+	return
+}
+
+// NewMetrics - Register the metrics of the RPCs, the pool of db and the runtime in a registry of their own,
+// exposed on path once Serve is called
+func NewMetrics(db *sql.DB, path string) (*Metrics, error) {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Number of RPCs completed on the server, by method and status code.",
+		}, []string{"grpc_method", "grpc_code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Time taken by the server to handle RPCs, by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"grpc_method"}),
+	}
+
+	collectors := []prometheus.Collector{
+		m.handled,
+		m.latency,
+		&dbStatsCollector{db: db},
+		&expvarCollector{
+			m:    PanicsTotal,
+			desc: prometheus.NewDesc("grpc_server_panics_total", "Number of panics recovered from, by method.", []string{"grpc_method"}, nil),
+		},
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	}
+
+	for _, c := range collectors {
+		if err := m.registry.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if path == "" {
+		path = DefaultMetricsPath
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	m.server = &http.Server{Handler: mux}
+
+	return m, nil
+}
+
+// Registry - Registry the metrics are gathered from, services can register their own collectors in it
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Listen - Open the port of the metrics, the caller checks the error before serving in the background
+func (m *Metrics) Listen(port int) (net.Listener, error) {
+	return net.Listen("tcp", ":"+strconv.Itoa(port))
+}
+
+// Serve - Serve the metrics on the listener until Close, it returns http.ErrServerClosed after Close
+func (m *Metrics) Serve(lis net.Listener) error {
+	return m.server.Serve(lis)
+}
+
+// Close - Stop serving the metrics
+func (m *Metrics) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+
+	return m.server.Shutdown(ctx)
+}
+
+// UnaryInterceptor - Count and time the unary calls
+func (m *Metrics) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamInterceptor - Count and time the streams
+func (m *Metrics) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observe(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+// observe - Record a completed call
+func (m *Metrics) observe(method string, start time.Time, err error) {
+	m.handled.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.latency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// dbStatsCollector - Collects the sql.DBStats of a pool on every scrape
+type dbStatsCollector struct {
+	db *sql.DB
+}
+
+var (
+	dbMaxOpenDesc      = prometheus.NewDesc("db_max_open_connections", "Maximum number of open connections to the database.", nil, nil)
+	dbOpenDesc         = prometheus.NewDesc("db_open_connections", "Number of established connections, in use and idle.", nil, nil)
+	dbInUseDesc        = prometheus.NewDesc("db_in_use_connections", "Number of connections currently in use.", nil, nil)
+	dbIdleDesc         = prometheus.NewDesc("db_idle_connections", "Number of idle connections.", nil, nil)
+	dbWaitCountDesc    = prometheus.NewDesc("db_wait_count_total", "Number of connections waited for.", nil, nil)
+	dbWaitDurationDesc = prometheus.NewDesc("db_wait_duration_seconds_total", "Time blocked waiting for a new connection.", nil, nil)
+	dbClosedDesc       = prometheus.NewDesc("db_closed_connections_total", "Number of connections closed, by reason.", []string{"reason"}, nil)
+)
+
+// Describe - Descriptions of the pool metrics
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{dbMaxOpenDesc, dbOpenDesc, dbInUseDesc, dbIdleDesc, dbWaitCountDesc, dbWaitDurationDesc, dbClosedDesc} {
+		ch <- d
+	}
+}
+
+// Collect - Current pool metrics
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.db.Stats()
+
+	ch <- prometheus.MustNewConstMetric(dbMaxOpenDesc, prometheus.GaugeValue, float64(s.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(s.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(s.InUse))
+	ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(s.Idle))
+	ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(s.WaitCount))
+	ch <- prometheus.MustNewConstMetric(dbWaitDurationDesc, prometheus.CounterValue, s.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(dbClosedDesc, prometheus.CounterValue, float64(s.MaxIdleClosed), "max_idle")
+	ch <- prometheus.MustNewConstMetric(dbClosedDesc, prometheus.CounterValue, float64(s.MaxIdleTimeClosed), "max_idle_time")
+	ch <- prometheus.MustNewConstMetric(dbClosedDesc, prometheus.CounterValue, float64(s.MaxLifetimeClosed), "max_lifetime")
+}
+
+// expvarCollector - Exposes an expvar map of counters as a counter with one label
+type expvarCollector struct {
+	m    *expvar.Map
+	desc *prometheus.Desc
+}
+
+// Describe - Description of the counter
+func (c *expvarCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect - Current value of every key of the map
+func (c *expvarCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.Do(func(kv expvar.KeyValue) {
+		if v, ok := kv.Value.(*expvar.Int); ok {
+			ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, float64(v.Value()), kv.Key)
+		}
+	})
+}
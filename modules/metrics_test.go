@@ -0,0 +1,62 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetrics(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	m, err := NewMetrics(db, "")
+	if err != nil {
+		t.Fatalf("NewMetrics() error = %v", err)
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/todo.ToDoService/Read"}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	notFound := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	_, _ = m.UnaryInterceptor()(context.Background(), nil, info, ok)
+	_, _ = m.UnaryInterceptor()(context.Background(), nil, info, ok)
+	_, _ = m.UnaryInterceptor()(context.Background(), nil, info, notFound)
+	// the panics are counted process wide, the sample is the count before plus this one
+	recovered := panics("/todo.ToDoService/Metrics") + 1
+	PanicsTotal.Add("/todo.ToDoService/Metrics", 1)
+
+	rec := httptest.NewRecorder()
+	m.server.Handler.ServeHTTP(rec, httptest.NewRequest("GET", DefaultMetricsPath, nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		`grpc_server_handled_total{grpc_code="OK",grpc_method="/todo.ToDoService/Read"} 2`,
+		`grpc_server_handled_total{grpc_code="NotFound",grpc_method="/todo.ToDoService/Read"} 1`,
+		`grpc_server_handling_seconds_count{grpc_method="/todo.ToDoService/Read"} 3`,
+		fmt.Sprintf(`grpc_server_panics_total{grpc_method="/todo.ToDoService/Metrics"} %d`, recovered),
+		`db_open_connections `,
+		`db_wait_count_total `,
+		`db_closed_connections_total{reason="max_idle"} 0`,
+		`db_closed_connections_total{reason="max_idle_time"} 0`,
+		`db_closed_connections_total{reason="max_lifetime"} 0`,
+		`go_goroutines `,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package mymodel
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Condition - A single comparison or, when Group is set, a parenthesised group of conditions.
+// Combine joins the condition to the previous one and is ignored on the first condition of a group
+type Condition struct {
+	Combine  string
+	Field    string
+	Operator string
+	Value    interface{} // In case of IN/BETWEEN it will be slice
+	Group    Conditions
+}
+
+// Conditions ...
+type Conditions []Condition
+
+// columnPattern plain column identifier
+var columnPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Where - Start the conditions with a comparison, Example: Where("id", OperatorEqual, 1).And("title", OperatorLike, "Go%")
+func Where(field string, operator string, value interface{}) Conditions {
+	return Conditions{}.And(field, operator, value)
+}
+
+// And - Append a comparison combined with AND
+func (c Conditions) And(field string, operator string, value interface{}) Conditions {
+	return append(c, Condition{Combine: CombineAND, Field: field, Operator: operator, Value: value})
+}
+
+// Or - Append a comparison combined with OR
+func (c Conditions) Or(field string, operator string, value interface{}) Conditions {
+	return append(c, Condition{Combine: CombineOR, Field: field, Operator: operator, Value: value})
+}
+
+// AndGroup - Append the group in parentheses combined with AND
+func (c Conditions) AndGroup(group Conditions) Conditions {
+	return append(c, Condition{Combine: CombineAND, Group: group})
+}
+
+// OrGroup - Append the group in parentheses combined with OR
+func (c Conditions) OrGroup(group Conditions) Conditions {
+	return append(c, Condition{Combine: CombineOR, Group: group})
+}
+
+// getWhereClause - Build the WHERE clause with its bind arguments.
+// When allowed is not empty only those columns may be referenced
+func (m *Model) getWhereClause(conditions Conditions, allowed map[string]bool) (cond string, args []interface{}, err error) {
+	var (
+		expr string
+	)
+
+	if expr, args, err = buildConditions(conditions, allowed); err != nil {
+		return
+	}
+
+	if expr != "" {
+		cond = " WHERE " + expr
+	}
+
+	return
+}
+
+// requireConditions - Fail with the message when the conditions render nothing, before the tenant scope is added.
+// Empty groups are skipped by buildConditions, they would turn the statement into one on every record
+func requireConditions(conditions Conditions, allowed map[string]bool, message string) error {
+	expr, _, err := buildConditions(conditions, allowed)
+	if err != nil {
+		return err
+	}
+
+	if expr == "" {
+		return errors.New(message)
+	}
+
+	return nil
+}
+
+// buildConditions - Render the conditions of one nesting level
+func buildConditions(conditions Conditions, allowed map[string]bool) (expr string, args []interface{}, err error) {
+	var (
+		parts []string
+	)
+
+	for _, c := range conditions {
+		var (
+			part     string
+			partArgs []interface{}
+		)
+
+		if c.Group != nil {
+			if part, partArgs, err = buildConditions(c.Group, allowed); err != nil {
+				return
+			}
+			// an empty group does not restrict anything
+			if part == "" {
+				continue
+			}
+			part = "(" + part + ")"
+		} else if part, partArgs, err = buildCondition(c, allowed); err != nil {
+			return
+		}
+
+		if len(parts) > 0 {
+			combine := strings.ToUpper(strings.TrimSpace(c.Combine))
+			if combine == "" {
+				combine = CombineAND
+			}
+			if combine != CombineAND && combine != CombineOR {
+				err = errors.New(SQLInvalidCombine)
+				return
+			}
+			part = combine + " " + part
+		}
+
+		parts = append(parts, part)
+		args = append(args, partArgs...)
+	}
+
+	expr = strings.Join(parts, " ")
+
+	return
+}
+
+// buildCondition - Render a single comparison, every value is passed as a bind argument
+func buildCondition(c Condition, allowed map[string]bool) (expr string, args []interface{}, err error) {
+	var (
+		vals []interface{}
+		ok   bool
+	)
+
+	if err = checkColumn(c.Field, allowed); err != nil {
+		return
+	}
+
+	operator := strings.ToUpper(strings.TrimSpace(c.Operator))
+
+	switch operator {
+	case OperatorEqual, OperatorNoEqual, OperatorGreaterThan, OperatorGreaterThanEqual, OperatorLessThan, OperatorLeasThanEqual,
+		OperatorLike, OperatorNotLike: // Example: Go% , %ang
+		expr = c.Field + " " + operator + " ?"
+		args = append(args, c.Value)
+	case OperatorIN, OperatorNotIN:
+		if vals, ok = sliceValues(c.Value); !ok || len(vals) == 0 {
+			err = errors.New(InvalidINClauseValue)
+			return
+		}
+		bindVars := strings.Trim(strings.Repeat(`?,`, len(vals)), ",")
+		expr = c.Field + " " + operator + " (" + bindVars + ")"
+		args = append(args, vals...)
+	case OperatorIsNull, OperatorIsNotNull:
+		expr = c.Field + " " + operator
+	case OperatorBetween, OperatorNotBetween:
+		if vals, ok = sliceValues(c.Value); !ok || len(vals) != 2 {
+			err = errors.New(InvalidBetweenClauseValue)
+			return
+		}
+		expr = c.Field + " " + operator + " ? AND ?"
+		args = append(args, vals...)
+	default:
+		err = errors.New(SQLInvalidOperator)
+	}
+
+	return
+}
+
+// checkColumn - Check the field is a plain identifier and, when allowed is not empty, one of the allowed columns
+func checkColumn(field string, allowed map[string]bool) error {
+	if !columnPattern.MatchString(field) || (len(allowed) > 0 && !allowed[field]) {
+		return errors.New(SQLInvalidColumn + ": " + field)
+	}
+
+	return nil
+}
+
+// allowedColumns - Columns the conditions may reference, the model Columns or else the given columns
+func (m *Model) allowedColumns(columns []string) (allowed map[string]bool) {
+	if len(m.Columns) > 0 {
+		columns = m.Columns
+	}
+
+	allowed = make(map[string]bool, len(columns)+1)
+	for _, c := range columns {
+		allowed[c] = true
+	}
+
+	// an empty set allows every column, so the tenant column only joins a non empty one
+	if m.TenantColumn != "" && len(allowed) > 0 {
+		allowed[m.TenantColumn] = true
+	}
+
+	return
+}
+
+// sliceValues - Get the elements of any slice or array value
+func sliceValues(value interface{}) (vals []interface{}, ok bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		vals = append(vals, v.Index(i).Interface())
+	}
+
+	return vals, true
+}
@@ -0,0 +1,109 @@
+package mymodel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestModel_getWhereClause(t *testing.T) {
+	m := &Model{Columns: []string{"id", "title", "reminder"}}
+
+	tests := []struct {
+		name       string
+		conditions Conditions
+		wantCond   string
+		wantArgs   []interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "Empty",
+			conditions: nil,
+		},
+		{
+			name:       "Single",
+			conditions: Where("id", OperatorEqual, 1),
+			wantCond:   " WHERE id = ?",
+			wantArgs:   []interface{}{1},
+		},
+		{
+			name: "Nested groups",
+			conditions: Where("title", OperatorLike, "Go%").
+				AndGroup(Where("reminder", OperatorGreaterThan, "a").
+					OrGroup(Where("reminder", OperatorEqual, "a").And("id", OperatorGreaterThan, 2))),
+			wantCond: " WHERE title LIKE ? AND (reminder > ? OR (reminder = ? AND id > ?))",
+			wantArgs: []interface{}{"Go%", "a", "a", 2},
+		},
+		{
+			name:       "Empty group skipped",
+			conditions: Where("id", OperatorEqual, 1).OrGroup(Conditions{}),
+			wantCond:   " WHERE id = ?",
+			wantArgs:   []interface{}{1},
+		},
+		{
+			name:       "Like value is bound",
+			conditions: Where("title", OperatorNotLike, `" OR 1=1 --`),
+			wantCond:   " WHERE title NOT LIKE ?",
+			wantArgs:   []interface{}{`" OR 1=1 --`},
+		},
+		{
+			name:       "Not in typed slice",
+			conditions: Where("id", OperatorNotIN, []int64{1, 2}),
+			wantCond:   " WHERE id NOT IN (?,?)",
+			wantArgs:   []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:       "Not between",
+			conditions: Where("id", OperatorNotBetween, []interface{}{1, 5}).And("title", OperatorIsNull, nil),
+			wantCond:   " WHERE id NOT BETWEEN ? AND ? AND title IS NULL",
+			wantArgs:   []interface{}{1, 5},
+		},
+		{
+			name:       "Empty IN",
+			conditions: Where("id", OperatorIN, []int{}),
+			wantErr:    true,
+		},
+		{
+			name:       "Between without two values",
+			conditions: Where("id", OperatorBetween, []interface{}{1}),
+			wantErr:    true,
+		},
+		{
+			name:       "Unknown operator",
+			conditions: Where("id", "<=>", 1),
+			wantErr:    true,
+		},
+		{
+			name:       "Unknown column",
+			conditions: Where("password", OperatorEqual, 1),
+			wantErr:    true,
+		},
+		{
+			name:       "Expression as column",
+			conditions: Where("id=1 OR id", OperatorEqual, 1),
+			wantErr:    true,
+		},
+		{
+			name:       "Unknown combine",
+			conditions: Conditions{{Field: "id", Operator: OperatorEqual, Value: 1}, {Combine: "XOR", Field: "id", Operator: OperatorEqual, Value: 2}},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCond, gotArgs, err := m.getWhereClause(tt.conditions, m.allowedColumns(nil))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Model.getWhereClause() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if gotCond != tt.wantCond {
+				t.Errorf("Model.getWhereClause() cond = %q, want %q", gotCond, tt.wantCond)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Model.getWhereClause() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package mymodel
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,8 +18,11 @@ const (
 	// DBTag ...
 	DBTag = "db"
 
+	// TagOmitEmpty db tag option to leave the column out of an insert when it holds the zero value
+	TagOmitEmpty = "omitempty"
+
 	// Combine
-	CombineADD = "ADD"
+	CombineAND = "AND"
 	CombineOR  = "OR"
 
 	// ISO8601Date ISO 8601 format with just the date
@@ -32,6 +37,9 @@ const (
 	// MaxLimit ...
 	MaxLimit = 50
 
+	// MaxPlaceholders maximum number of bind variables mysql accepts in one statement
+	MaxPlaceholders = 65535
+
 	// MinOffset ...
 	MinOffset = 0
 
@@ -44,9 +52,18 @@ const (
 	// NoInsertRecordProvided no insert record provided
 	NoInsertRecordProvided = "no insert record provided"
 
+	// InvalidInsertRecord invalid insert record
+	InvalidInsertRecord = "insert record should be a struct or a slice of structs with db tagged fields"
+
 	// NoUpdateRecordProvided no insert record provided
 	NoUpdateRecordProvided = "no update record provided"
 
+	// NoUpdateConditionProvided no update condition provided
+	NoUpdateConditionProvided = "no update condition provided"
+
+	// NoDeleteConditionProvided no delete condition provided
+	NoDeleteConditionProvided = "no delete condition provided"
+
 	// SQLInvalidOperator invalid sql operator
 	SQLInvalidOperator = "invalid sql operator"
 
@@ -59,9 +76,15 @@ const (
 	// SQLNoRowsErrorCode sql: no rows in result set
 	SQLNoRowsErrorCode = "sql: no rows in result set"
 
-	InvalidINClauseValue = "IN clause values should be a slice of interface"
+	// SQLInvalidCombine invalid sql combine
+	SQLInvalidCombine = "invalid sql combine, should be AND or OR"
+
+	// SQLInvalidColumn invalid sql column
+	SQLInvalidColumn = "invalid sql column"
 
-	InvalidBetweenClauseValue = "value should be a slice of interface in case of between clause"
+	InvalidINClauseValue = "IN clause values should be a non empty slice"
+
+	InvalidBetweenClauseValue = "value should be a slice of two values in case of between clause"
 
 	// DateField ...
 	DateField = "date"
@@ -88,39 +111,39 @@ const (
 	OperatorLessThan         = "<"
 	OperatorLeasThanEqual    = "<="
 	OperatorIN               = "IN"
+	OperatorNotIN            = "NOT IN"
 	OperatorIsNull           = "IS NULL"
 	OperatorIsNotNull        = "IS NOT NULL"
 	OperatorLike             = "LIKE"
+	OperatorNotLike          = "NOT LIKE"
 	OperatorBetween          = "BETWEEN"
+	OperatorNotBetween       = "NOT BETWEEN"
 )
 
 // Model represents the core model
 type Model struct {
 	DB        *sqlx.DB `db:"-" json:"-"`
+	Tx        *sqlx.Tx `db:"-" json:"-"` // set while the model runs in a transaction, see RunInTx
 	Limit     int      `db:"-" json:"-"`
 	Offset    int      `db:"-" json:"-"`
 	SortOrder []string `db:"-" json:"-"`
 	CacheThis bool     `db:"-" json:"-"`
 	TableName string   `db:"-" json:"-"`
-}
+	Columns   []string `db:"-" json:"-"` // columns allowed in conditions, see ColumnNames
 
-// Condition ...
-type Condition struct {
-	Combine  string
-	Field    string
-	Operator string
-	Value    interface{} // In case of BETWEEN it will be slice
+	// TenantColumn scopes every query to the tenant stored in the context with WithTenant, when set
+	TenantColumn string `db:"-" json:"-"`
 }
 
-// Conditions ...
-type Conditions []Condition
-
 // SortDirection ...
 var SortDirection = map[string]string{
 	"-": "ASC",
 	"+": "DESC",
 }
 
+// likeEscaper escapes the LIKE wildcards and the escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // UnixTimestamp return utc timestamp
 func UnixTimestamp() string {
 	return fmt.Sprintf("%d", time.Now().Local().Unix())
@@ -134,18 +157,39 @@ func UnixToMysqlTime(sec string, nsec string) string {
 	return time.Unix(iSec, iNsec).Format(SQLDatetime)
 }
 
+// ParseDatetime - Parse a datetime column read into a string, in SQLDatetime format
+// or, when the connection parses times, in RFC 3339
+func ParseDatetime(value string) (time.Time, error) {
+	t, err := time.Parse(SQLDatetime, value)
+	if err != nil {
+		return time.Parse(time.RFC3339Nano, value)
+	}
+
+	return t, nil
+}
+
+// EscapeLike - Escape the LIKE wildcards so the value matches literally
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
 // Select ...
-func (m *Model) Select(dest interface{}, conditions Conditions) (err error) {
+func (m *Model) Select(ctx context.Context, dest interface{}, conditions Conditions) (err error) {
 	var (
 		sql, whereClause string
 		args             []interface{}
 		columns, order   []string
 	)
 
-	columns, _ = m.getQueryDetail(m)
+	if conditions, err = m.scope(ctx, conditions); err != nil {
+		return
+	}
+
+	columns = ColumnNames(dest)
 	sql = fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ","), m.TableName)
 
-	if whereClause, args, err = m.getWhereClause(conditions); err != nil {
+	allowed := m.allowedColumns(columns)
+	if whereClause, args, err = m.getWhereClause(conditions, allowed); err != nil {
 		return
 	}
 
@@ -157,6 +201,9 @@ func (m *Model) Select(dest interface{}, conditions Conditions) (err error) {
 
 		for _, s := range m.SortOrder {
 			d, c := string(s[0]), string(s[1:])
+			if err = checkColumn(c, allowed); err != nil {
+				return
+			}
 			order = append(order, c+" "+SortDirection[d])
 
 		}
@@ -167,7 +214,7 @@ func (m *Model) Select(dest interface{}, conditions Conditions) (err error) {
 		sql += " LIMIT " + strconv.Itoa(m.Offset) + "," + strconv.Itoa(m.Limit)
 	}
 
-	return m.DB.Select(dest, sql, args...)
+	return m.selectContext(ctx, dest, sql, args...)
 }
 
 // SelectComplex ...
@@ -175,150 +222,278 @@ func (m *Model) SelectComplex(dest interface{}) {
 
 }
 
-// Insert - To insert single record
-func (m *Model) Insert(insertSet interface{}) (res sql.Result, err error) {
+// Insert - To insert a single record or a slice of records with one statement
+func (m *Model) Insert(ctx context.Context, insertSet interface{}) (res sql.Result, err error) {
 	var (
-		rows, args   []interface{}
-		columns, val []string
-		sql, query   string
+		rows    [][]interface{}
+		columns []string
+		query   string
+		args    []interface{}
 	)
 
-	// prepare values place holders
-	// get all the values
-	for _, v := range r {
-		ins = append(ins, v)
+	if columns, rows, err = m.getQueryDetail(insertSet); err != nil {
+		return
 	}
-	rows = append(rows, ins)
-	val = append(val, "(?)")
-	ins = nil
 
-	columns, args = m.getQueryDetail(insertSet)
+	if columns, rows, err = m.scopeRows(ctx, columns, rows); err != nil {
+		return
+	}
 
-	sql = fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", m.TableName, strings.Join(columns, ","), strings.Join(val, ","))
-	res, err = m.DB.Exec(query, args...)
+	query, args = m.insertQuery(columns, rows)
+	res, err = m.execContext(ctx, query, args...)
 
 	return
 }
 
-// Update -
-func (m *Model) Update(set map[string]interface{}, conditions map[string]interface{}) (res sql.Result, err error) {
-
+// InsertBatch - To insert a slice of records in chunks of at most size rows.
+// The chunk size is capped so a statement never exceeds MaxPlaceholders bind variables, size <= 0 uses the cap.
+// Chunks are executed one by one, wrap the call in a transaction when the batch must be atomic.
+func (m *Model) InsertBatch(ctx context.Context, insertSet interface{}, size int) (results []sql.Result, err error) {
 	var (
-		args                    []interface{}
-		updateSet, conditionSet []string
-		query                   string
+		rows    [][]interface{}
+		columns []string
+		res     sql.Result
 	)
 
-	if set != nil {
-		err = errors.New(NoUpdateRecordProvided)
+	if columns, rows, err = m.getQueryDetail(insertSet); err != nil {
 		return
 	}
 
-	for c, v := range set {
-		updateSet = append(updateSet, c+" = ?")
-		args = append(args, v)
+	if columns, rows, err = m.scopeRows(ctx, columns, rows); err != nil {
+		return
 	}
 
-	query = fmt.Sprintf("UPDATE %s SET %s", m.TableName, strings.Join(updateSet, ","))
+	if max := MaxPlaceholders / len(columns); size <= 0 || size > max {
+		size = max
+	}
 
-	if conditions != nil {
-		for c, v := range conditions {
-			conditionSet = append(conditionSet, c+" = ?")
-			args = append(args, v)
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
 		}
-		if len(conditionSet) > 0 {
-			query += " WHERE " + strings.Join(conditionSet, ",")
+
+		query, args := m.insertQuery(columns, rows[start:end])
+		if res, err = m.execContext(ctx, query, args...); err != nil {
+			return
 		}
+		results = append(results, res)
 	}
 
-	res, err = m.DB.Exec(query, args...)
 	return
 }
 
-// Delete ...
-func (m *Model) Delete(query string, args ...interface{}) (res sql.Result, err error) {
-	//TODO:
-	res, err = m.DB.Exec(query, args...)
+// insertQuery - Build the multi row insert statement with its arguments
+func (m *Model) insertQuery(columns []string, rows [][]interface{}) (query string, args []interface{}) {
+	var (
+		val []string
+	)
+
+	placeholder := "(" + strings.Trim(strings.Repeat("?,", len(columns)), ",") + ")"
+	for _, r := range rows {
+		val = append(val, placeholder)
+		args = append(args, r...)
+	}
+
+	query = fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", m.TableName, strings.Join(columns, ","), strings.Join(val, ","))
+
 	return
 }
 
-// getWhereClause ...
-func (m *Model) getWhereClause(conditions Conditions) (cond string, args []interface{}, err error) {
+// Update - To update the records matching the conditions
+func (m *Model) Update(ctx context.Context, set map[string]interface{}, conditions Conditions) (res sql.Result, err error) {
 
 	var (
-		conds []string
+		args, whereArgs   []interface{}
+		updateSet, fields []string
+		query, where      string
 	)
-	if len(conditions) == 0 {
+
+	if len(set) == 0 {
+		err = errors.New(NoUpdateRecordProvided)
 		return
 	}
 
-	for _, c := range conditions {
-		switch c.Operator {
-		case OperatorEqual, OperatorNoEqual, OperatorGreaterThan, OperatorGreaterThanEqual, OperatorLessThan, OperatorLeasThanEqual:
-			conds = append(conds, c.Combine+" "+c.Field+""+c.Operator+"?")
-			args = append(args, c.Value)
-			break
-		case OperatorIN:
-			v := reflect.ValueOf(c.Value).Type().Kind()
-			if v != reflect.Slice {
-				err = errors.New(InvalidINClauseValue)
-				return
-			}
-			bindVars := strings.Trim(strings.Repeat(`?,`, len(c.Value.([]interface{}))), ",")
-
-			t := fmt.Sprintf(c.Combine+` %s IN (%s)`, c.Field, bindVars)
-			conds = append(conds, t)
-			args = append(args, c.Value.([]interface{})...)
-			break
-		case OperatorIsNull, OperatorIsNotNull:
-			conds = append(conds, c.Combine+` `+c.Field+` `+c.Operator)
-			break
-		case OperatorLike:
-			conds = append(conds, c.Combine+` `+c.Field+` `+c.Operator+` "`+c.Value.(string)+`"`) // Example: Go% , %ang
-			break
-		case OperatorBetween:
-			v := reflect.ValueOf(c.Value).Type().Kind().String()
-			if v != "slice" {
-				err = errors.New(InvalidBetweenClauseValue)
-				return
-			}
-
-			vals := c.Value.([]interface{})
-			clause := fmt.Sprintf(c.Combine+" %s BETWEEN ? AND ?", c.Field)
-			conds = append(conds, clause)
-			args = append(args, vals...)
-			break
-		default:
-			break
+	// keep the column order stable so the generated statement is deterministic
+	for c := range set {
+		fields = append(fields, c)
+	}
+	sort.Strings(fields)
 
+	allowed := m.allowedColumns(nil)
+	for _, c := range fields {
+		if err = checkColumn(c, allowed); err != nil {
+			return
 		}
+		if m.TenantColumn != "" && c == m.TenantColumn {
+			err = errors.New(TenantColumnNotWritable)
+			return
+		}
+		updateSet = append(updateSet, c+" = ?")
+		args = append(args, set[c])
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET %s", m.TableName, strings.Join(updateSet, ","))
+
+	if err = requireConditions(conditions, allowed, NoUpdateConditionProvided); err != nil {
+		return
+	}
+
+	if conditions, err = m.scope(ctx, conditions); err != nil {
+		return
+	}
+
+	if where, whereArgs, err = m.getWhereClause(conditions, allowed); err != nil {
+		return
 	}
 
-	if len(conds) > 0 {
-		cond = fmt.Sprintf(" WHERE %s", strings.Join(conds, " "))
+	query += where
+	args = append(args, whereArgs...)
+
+	res, err = m.execContext(ctx, query, args...)
+	return
+}
+
+// Delete - To delete the records matching the conditions
+func (m *Model) Delete(ctx context.Context, conditions Conditions) (res sql.Result, err error) {
+	var (
+		args         []interface{}
+		query, where string
+	)
+
+	allowed := m.allowedColumns(nil)
+	if err = requireConditions(conditions, allowed, NoDeleteConditionProvided); err != nil {
+		return
+	}
+
+	if conditions, err = m.scope(ctx, conditions); err != nil {
+		return
+	}
+
+	if where, args, err = m.getWhereClause(conditions, allowed); err != nil {
+		return
 	}
 
+	query = fmt.Sprintf("DELETE FROM %s%s", m.TableName, where)
+
+	res, err = m.execContext(ctx, query, args...)
 	return
 }
 
-func (m *Model) prepareQuery() (columns []string, ) {
+// column - Struct field mapped to a table column through the db tag
+type column struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// getColumns - Get the columns mapped by the db tags of the struct type.
+// Fields tagged "-", untagged fields and embedded structs like Model are skipped
+func getColumns(t reflect.Type) (columns []column) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get(DBTag)
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		c := column{name: opts[0], index: i}
+		for _, o := range opts[1:] {
+			if o == TagOmitEmpty {
+				c.omitEmpty = true
+			}
+		}
+		columns = append(columns, c)
+	}
+
 	return
 }
 
-// getQueryDetail ...
-func (m *Model) getQueryDetail(dest interface{}) (columns []string, args []interface{}) {
+// ColumnNames - Get the columns mapped by the db tags of a struct, a pointer to it or a slice of them
+func ColumnNames(dest interface{}) (names []string) {
+	t := reflect.TypeOf(dest)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
 
-	v := reflect.ValueOf(dest).Elem()
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
 
-	for i := 0; i < v.NumField(); i++ {
-		f := v.Type().Field(i)
-		val := v.Type().Field(i).V
-		dbColumn := f.Tag.Get(DBTag)
-		if dbColumn != "" || dbColumn != "-" {
-			columns = append(columns, dbColumn)
-			args = append(colum)
+	for _, c := range getColumns(t) {
+		names = append(names, c.name)
+	}
+
+	return
+}
+
+// getQueryDetail - Get the columns and the values of every record of a struct or a slice of structs.
+// A column tagged omitempty is left out when it holds the zero value in every record
+func (m *Model) getQueryDetail(insertSet interface{}) (columns []string, rows [][]interface{}, err error) {
+	var (
+		records []reflect.Value
+		fields  []column
+	)
+
+	v := reflect.Indirect(reflect.ValueOf(insertSet))
+	switch v.Kind() {
+	case reflect.Struct:
+		records = append(records, v)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			records = append(records, reflect.Indirect(v.Index(i)))
+		}
+	}
+
+	if len(records) == 0 {
+		err = errors.New(NoInsertRecordProvided)
+		return
+	}
+
+	for _, r := range records {
+		if r.Kind() != reflect.Struct || r.Type() != records[0].Type() {
+			err = errors.New(InvalidInsertRecord)
+			return
 		}
 	}
 
+	for _, c := range getColumns(records[0].Type()) {
+		if c.omitEmpty && isZeroColumn(records, c.index) {
+			continue
+		}
+		fields = append(fields, c)
+		columns = append(columns, c.name)
+	}
+
+	if len(columns) == 0 {
+		err = errors.New(InvalidInsertRecord)
+		return
+	}
+
+	for _, r := range records {
+		row := make([]interface{}, 0, len(fields))
+		for _, c := range fields {
+			row = append(row, r.Field(c.index).Interface())
+		}
+		rows = append(rows, row)
+	}
+
 	return
 }
+
+// isZeroColumn - Check if the field holds the zero value in all the records
+func isZeroColumn(records []reflect.Value, index int) bool {
+	for _, r := range records {
+		f := r.Field(index)
+		if !reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface()) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,191 @@
+package mymodel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+type record struct {
+	Model    `db:"-"`
+	ID       int64  `db:"id,omitempty"`
+	Name     string `db:"name"`
+	Ignored  string `db:"-"`
+	Untagged string
+}
+
+func newModel(t *testing.T) (*Model, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	return &Model{DB: sqlx.NewDb(db, "mysql"), TableName: "Record"}, mock
+}
+
+func TestModel_Insert(t *testing.T) {
+	m, mock := newModel(t)
+	defer m.DB.Close()
+
+	tests := []struct {
+		name      string
+		insertSet interface{}
+		mock      func()
+		wantErr   bool
+	}{
+		{
+			name:      "Struct",
+			insertSet: record{Name: "a", Ignored: "x"},
+			mock: func() {
+				mock.ExpectExec(`INSERT INTO Record \(name\) VALUES \(\?\)$`).WithArgs("a").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:      "Slice of pointers",
+			insertSet: []*record{{Name: "a"}, {Name: "b"}},
+			mock: func() {
+				mock.ExpectExec(`INSERT INTO Record \(name\) VALUES \(\?\),\(\?\)$`).WithArgs("a", "b").
+					WillReturnResult(sqlmock.NewResult(1, 2))
+			},
+		},
+		{
+			name:      "Omitempty column set",
+			insertSet: []record{{Name: "a"}, {ID: 7, Name: "b"}},
+			mock: func() {
+				mock.ExpectExec(`INSERT INTO Record \(id,name\) VALUES \(\?,\?\),\(\?,\?\)$`).WithArgs(0, "a", 7, "b").
+					WillReturnResult(sqlmock.NewResult(7, 2))
+			},
+		},
+		{
+			name:      "Empty slice",
+			insertSet: []record{},
+			mock:      func() {},
+			wantErr:   true,
+		},
+		{
+			name:      "Not a struct",
+			insertSet: []int{1},
+			mock:      func() {},
+			wantErr:   true,
+		},
+		{
+			name:      "Exec failed",
+			insertSet: record{Name: "a"},
+			mock: func() {
+				mock.ExpectExec("INSERT INTO Record").WillReturnError(errors.New("INSERT failed"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			if _, err := m.Insert(context.Background(), tt.insertSet); (err != nil) != tt.wantErr {
+				t.Errorf("Model.Insert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Model.Insert() %v", err)
+			}
+		})
+	}
+}
+
+func TestModel_InsertBatch(t *testing.T) {
+	m, mock := newModel(t)
+	defer m.DB.Close()
+
+	insertSet := []record{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	mock.ExpectExec(`INSERT INTO Record \(name\) VALUES \(\?\),\(\?\)$`).WithArgs("a", "b").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectExec(`INSERT INTO Record \(name\) VALUES \(\?\)$`).WithArgs("c").
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	results, err := m.InsertBatch(context.Background(), insertSet, 2)
+	if err != nil {
+		t.Fatalf("Model.InsertBatch() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Model.InsertBatch() returned %d results, want 2", len(results))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Model.InsertBatch() %v", err)
+	}
+}
+
+func TestModel_InsertBatch_PlaceholderLimit(t *testing.T) {
+	m, mock := newModel(t)
+	defer m.DB.Close()
+
+	insertSet := make([]record, MaxPlaceholders+1)
+
+	mock.ExpectExec("INSERT INTO Record").WillReturnResult(sqlmock.NewResult(1, MaxPlaceholders))
+	mock.ExpectExec(`INSERT INTO Record \(name\) VALUES \(\?\)$`).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if _, err := m.InsertBatch(context.Background(), insertSet, 0); err != nil {
+		t.Fatalf("Model.InsertBatch() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Model.InsertBatch() %v", err)
+	}
+}
+
+func TestModel_UpdateDelete_NoConditions(t *testing.T) {
+	m, mock := newModel(t)
+	ctx := context.Background()
+	set := map[string]interface{}{"name": "b"}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr string
+	}{
+		{
+			name: "Update without conditions",
+			run: func() error {
+				_, err := m.Update(ctx, set, nil)
+				return err
+			},
+			wantErr: NoUpdateConditionProvided,
+		},
+		{
+			name: "Update with an empty group",
+			run: func() error {
+				_, err := m.Update(ctx, set, Conditions{}.OrGroup(Conditions{}))
+				return err
+			},
+			wantErr: NoUpdateConditionProvided,
+		},
+		{
+			name: "Delete without conditions",
+			run: func() error {
+				_, err := m.Delete(ctx, Conditions{})
+				return err
+			},
+			wantErr: NoDeleteConditionProvided,
+		},
+		{
+			name: "Delete with an empty group",
+			run: func() error {
+				_, err := m.Delete(ctx, Conditions{}.AndGroup(Conditions{}))
+				return err
+			},
+			wantErr: NoDeleteConditionProvided,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %s", err, tt.wantErr)
+			}
+			// no statement may reach the database
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package mymodel
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	// InvalidPageToken invalid page token
+	InvalidPageToken = "invalid page token"
+)
+
+// EncodePageToken - Encode the cursor into an opaque token signed with the key
+func EncodePageToken(key []byte, cursor interface{}) (token string, err error) {
+	var (
+		payload []byte
+	)
+
+	if payload, err = json.Marshal(cursor); err != nil {
+		return
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+
+	token = base64.RawURLEncoding.EncodeToString(append(mac.Sum(nil), payload...))
+
+	return
+}
+
+// DecodePageToken - Verify the token signature and decode it into the cursor.
+// A token that is malformed or was not signed with the key returns InvalidPageToken
+func DecodePageToken(key []byte, token string, cursor interface{}) (err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) <= sha256.Size {
+		return errors.New(InvalidPageToken)
+	}
+
+	sig, payload := raw[:sha256.Size], raw[sha256.Size:]
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return errors.New(InvalidPageToken)
+	}
+
+	if err = json.Unmarshal(payload, cursor); err != nil {
+		return errors.New(InvalidPageToken)
+	}
+
+	return
+}
@@ -0,0 +1,88 @@
+package mymodel
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// NoTenantInContext no tenant in context
+	NoTenantInContext = "no tenant in context, the model is scoped to the tenant of the caller"
+
+	// TenantColumnNotWritable tenant column can not be updated
+	TenantColumnNotWritable = "tenant column can not be updated"
+)
+
+// tenantKey is the context key of the tenant
+type tenantKey struct{}
+
+// WithTenant - Store the tenant of the caller in the context, models with a TenantColumn are scoped to it
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom - Get the tenant of the caller, false when the context has none
+func TenantFrom(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// tenant - Tenant the queries of the model are scoped to, empty when the model is not scoped.
+// A scoped model fails without a tenant in the context rather than reading every tenant
+func (m *Model) tenant(ctx context.Context) (string, error) {
+	if m.TenantColumn == "" {
+		return "", nil
+	}
+
+	tenant, ok := TenantFrom(ctx)
+	if !ok {
+		return "", errors.New(NoTenantInContext)
+	}
+
+	return tenant, nil
+}
+
+// scope - Restrict the conditions to the tenant of the context. The conditions are grouped,
+// so an OR among them can not reach the rows of another tenant
+func (m *Model) scope(ctx context.Context, conditions Conditions) (Conditions, error) {
+	tenant, err := m.tenant(ctx)
+	if err != nil || tenant == "" {
+		return conditions, err
+	}
+
+	scoped := Where(m.TenantColumn, OperatorEqual, tenant)
+	if len(conditions) > 0 {
+		scoped = scoped.AndGroup(conditions)
+	}
+
+	return scoped, nil
+}
+
+// scopeRows - Set the tenant column of every row to the tenant of the context, whatever the records hold
+func (m *Model) scopeRows(ctx context.Context, columns []string, rows [][]interface{}) ([]string, [][]interface{}, error) {
+	tenant, err := m.tenant(ctx)
+	if err != nil || tenant == "" {
+		return columns, rows, err
+	}
+
+	index := -1
+	for i, c := range columns {
+		if c == m.TenantColumn {
+			index = i
+		}
+	}
+
+	if index < 0 {
+		columns = append(columns, m.TenantColumn)
+		for i := range rows {
+			rows[i] = append(rows[i], tenant)
+		}
+		return columns, rows, nil
+	}
+
+	for _, r := range rows {
+		r[index] = tenant
+	}
+
+	return columns, rows, nil
+}
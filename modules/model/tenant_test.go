@@ -0,0 +1,156 @@
+package mymodel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type ownedRecord struct {
+	Model  `db:"-"`
+	ID     int64  `db:"id,omitempty"`
+	Name   string `db:"name"`
+	Tenant string `db:"tenant"`
+}
+
+func TestModel_TenantScope(t *testing.T) {
+	m, mock := newModel(t)
+	defer m.DB.Close()
+	m.Columns = ColumnNames(ownedRecord{})
+	m.TenantColumn = "tenant"
+
+	ctx := WithTenant(context.Background(), "acme")
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		run     func(ctx context.Context) error
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "Select groups the conditions",
+			ctx:  ctx,
+			run: func(ctx context.Context) error {
+				var dest []ownedRecord
+				return m.Select(ctx, &dest, Where("id", OperatorEqual, 1).Or("name", OperatorEqual, "a"))
+			},
+			mock: func() {
+				mock.ExpectQuery(`SELECT id,name,tenant FROM Record WHERE tenant = \? AND \(id = \? OR name = \?\)$`).
+					WithArgs("acme", 1, "a").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "tenant"}))
+			},
+		},
+		{
+			name: "Select without conditions",
+			ctx:  ctx,
+			run: func(ctx context.Context) error {
+				var dest []ownedRecord
+				return m.Select(ctx, &dest, nil)
+			},
+			mock: func() {
+				mock.ExpectQuery(`SELECT id,name,tenant FROM Record WHERE tenant = \?$`).
+					WithArgs("acme").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "tenant"}))
+			},
+		},
+		{
+			name: "Insert overrides the tenant",
+			ctx:  ctx,
+			run: func(ctx context.Context) error {
+				_, err := m.Insert(ctx, []ownedRecord{{Name: "a", Tenant: "other"}})
+				return err
+			},
+			mock: func() {
+				mock.ExpectExec(`INSERT INTO Record \(name,tenant\) VALUES \(\?,\?\)$`).WithArgs("a", "acme").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "Insert adds the tenant column",
+			ctx:  ctx,
+			run: func(ctx context.Context) error {
+				_, err := m.Insert(ctx, record{Name: "a"})
+				return err
+			},
+			mock: func() {
+				mock.ExpectExec(`INSERT INTO Record \(name,tenant\) VALUES \(\?,\?\)$`).WithArgs("a", "acme").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "Update",
+			ctx:  ctx,
+			run: func(ctx context.Context) error {
+				_, err := m.Update(ctx, map[string]interface{}{"name": "b"}, Where("id", OperatorEqual, 1))
+				return err
+			},
+			mock: func() {
+				mock.ExpectExec(`UPDATE Record SET name = \? WHERE tenant = \? AND \(id = \?\)$`).WithArgs("b", "acme", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Update of the tenant",
+			ctx:  ctx,
+			run: func(ctx context.Context) error {
+				_, err := m.Update(ctx, map[string]interface{}{"tenant": "other"}, Where("id", OperatorEqual, 1))
+				return err
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name: "Delete",
+			ctx:  ctx,
+			run: func(ctx context.Context) error {
+				_, err := m.Delete(ctx, Where("id", OperatorEqual, 1))
+				return err
+			},
+			mock: func() {
+				mock.ExpectExec(`DELETE FROM Record WHERE tenant = \? AND \(id = \?\)$`).WithArgs("acme", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Delete needs a condition besides the tenant",
+			ctx:  ctx,
+			run: func(ctx context.Context) error {
+				_, err := m.Delete(ctx, nil)
+				return err
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name: "Delete of an empty group needs a condition besides the tenant",
+			ctx:  ctx,
+			run: func(ctx context.Context) error {
+				_, err := m.Delete(ctx, Conditions{}.AndGroup(Conditions{}))
+				return err
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name: "No tenant in context",
+			ctx:  context.Background(),
+			run: func(ctx context.Context) error {
+				var dest []ownedRecord
+				return m.Select(ctx, &dest, nil)
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			if err := tt.run(tt.ctx); (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package mymodel
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+
+	"grpoc/modules/trace"
+)
+
+// selectContext - Run the select in a span of the trace of the context
+func (m *Model) selectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := m.startSpan(ctx, query, args)
+	defer span.End()
+
+	err = m.executor().SelectContext(ctx, dest, query, args...)
+	span.SetError(err)
+
+	if v := reflect.Indirect(reflect.ValueOf(dest)); err == nil && v.Kind() == reflect.Slice {
+		span.SetAttribute("db.rows_returned", v.Len())
+	}
+
+	return
+}
+
+// execContext - Run the statement in a span of the trace of the context
+func (m *Model) execContext(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
+	ctx, span := m.startSpan(ctx, query, args)
+	defer span.End()
+
+	res, err = m.executor().ExecContext(ctx, query, args...)
+	span.SetError(err)
+
+	if err == nil {
+		if n, e := res.RowsAffected(); e == nil {
+			span.SetAttribute("db.rows_affected", n)
+		}
+	}
+
+	return
+}
+
+// startSpan - Start the span of a query. The statement only holds placeholders,
+// the values are left out so no user data ends up in the traces
+func (m *Model) startSpan(ctx context.Context, query string, args []interface{}) (context.Context, *trace.Span) {
+	operation := strings.ToUpper(strings.SplitN(query, " ", 2)[0])
+
+	ctx, span := trace.Start(ctx, "mysql."+strings.ToLower(operation), trace.KindClient)
+	span.SetAttribute("db.system", "mysql")
+	span.SetAttribute("db.operation", operation)
+	span.SetAttribute("db.table", m.TableName)
+	span.SetAttribute("db.statement", query)
+	span.SetAttribute("db.args", len(args))
+	span.SetAttribute("db.in_transaction", m.Tx != nil)
+
+	return ctx, span
+}
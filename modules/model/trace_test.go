@@ -0,0 +1,43 @@
+package mymodel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"grpoc/modules/trace"
+)
+
+func TestModel_QuerySpans(t *testing.T) {
+	m, mock := newModel(t)
+	defer m.DB.Close()
+
+	exporter := &trace.RecordingExporter{}
+	ctx, root := trace.NewTracer(exporter, 1).Start(context.Background(), "rpc", trace.KindServer)
+
+	mock.ExpectExec(`UPDATE Record SET name = \? WHERE id = \?$`).WithArgs("secret", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := m.Update(ctx, map[string]interface{}{"name": "secret"}, Where("id", OperatorEqual, 1)); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	root.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != "mysql.update" || span.ParentID != root.SpanContext().SpanID.String() {
+		t.Errorf("query span = %+v, want mysql.update child of the rpc", span)
+	}
+	if span.Attributes["db.statement"] != "UPDATE Record SET name = ? WHERE id = ?" || span.Attributes["db.rows_affected"] != int64(1) {
+		t.Errorf("query span attributes = %v", span.Attributes)
+	}
+	for k, v := range span.Attributes {
+		if v == "secret" {
+			t.Errorf("query span attribute %s holds an argument", k)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package mymodel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"grpoc/modules/trace"
+)
+
+const (
+	// MaxTxRetries number of times a transaction is retried after a deadlock or a lock wait timeout
+	MaxTxRetries = 3
+
+	// TxRetryBackoff wait before the first retry, doubled on every further retry
+	TxRetryBackoff = 50 * time.Millisecond
+
+	// MySQL error numbers of the transient lock errors
+	errLockDeadlock    = 1213
+	errLockWaitTimeout = 1205
+)
+
+// executor is implemented by both *sqlx.DB and *sqlx.Tx
+type executor interface {
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// executor - The transaction the model is bound to or else the database
+func (m *Model) executor() executor {
+	if m.Tx != nil {
+		return m.Tx
+	}
+
+	return m.DB
+}
+
+// RunInTx - Run fn in a transaction that is committed when fn returns nil and rolled back otherwise.
+// tx is a copy of the model bound to the transaction, other models join it with their WithTx.
+// On a deadlock or lock wait timeout the whole fn is retried up to MaxTxRetries times, so it must not have side effects
+// outside the database. When the model is already bound to a transaction fn simply runs in it
+func (m *Model) RunInTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *Model) error) (err error) {
+	if m.Tx != nil {
+		return fn(m)
+	}
+
+	ctx, span := trace.Start(ctx, "mysql.transaction", trace.KindClient)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	backoff := TxRetryBackoff
+	for attempt := 0; ; attempt++ {
+		span.SetAttribute("db.attempts", attempt+1)
+		if err = m.runInTx(ctx, opts, fn); err == nil || attempt == MaxTxRetries || !isRetryable(err) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+// runInTx - Run fn once in a new transaction
+func (m *Model) runInTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *Model) error) (err error) {
+	var (
+		tx *sqlx.Tx
+	)
+
+	if tx, err = m.DB.BeginTxx(ctx, opts); err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	txModel := *m
+	txModel.Tx = tx
+
+	if err = fn(&txModel); err != nil {
+		_ = tx.Rollback()
+		return
+	}
+
+	return tx.Commit()
+}
+
+// isRetryable - Check if the error is a transient lock error the transaction can be retried after, wrapped or not
+func isRetryable(err error) bool {
+	var e *mysql.MySQLError
+	if errors.As(err, &e) {
+		return e.Number == errLockDeadlock || e.Number == errLockWaitTimeout
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+package mymodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestModel_RunInTx(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: errLockDeadlock, Message: "Deadlock found when trying to get lock"}
+	insert := func(tx *Model) error {
+		_, err := tx.Insert(context.Background(), record{Name: "a"})
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		fn      func(tx *Model) error
+		mock    func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "Commit",
+			fn:   insert,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO Record").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Rollback",
+			fn:   insert,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO Record").WillReturnError(errors.New("INSERT failed"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Retried after deadlock",
+			fn:   insert,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO Record").WillReturnError(deadlock)
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO Record").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Retried after wrapped deadlock",
+			fn: func(tx *Model) error {
+				if err := insert(tx); err != nil {
+					return fmt.Errorf("failed to insert: %w", err)
+				}
+				return nil
+			},
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO Record").WillReturnError(deadlock)
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO Record").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Retries exhausted",
+			fn:   insert,
+			mock: func(mock sqlmock.Sqlmock) {
+				for i := 0; i <= MaxTxRetries; i++ {
+					mock.ExpectBegin()
+					mock.ExpectExec("INSERT INTO Record").WillReturnError(deadlock)
+					mock.ExpectRollback()
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "Nested runs in the outer transaction",
+			fn: func(tx *Model) error {
+				return tx.RunInTx(context.Background(), nil, insert)
+			},
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO Record").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, mock := newModel(t)
+			defer m.DB.Close()
+
+			tt.mock(mock)
+			if err := m.RunInTx(context.Background(), nil, tt.fn); (err != nil) != tt.wantErr {
+				t.Errorf("Model.RunInTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if m.Tx != nil {
+				t.Errorf("Model.RunInTx() bound the model itself to the transaction")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Model.RunInTx() %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package modules
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/sarulabs/di"
+	"go.uber.org/zap"
+)
+
+const (
+	InstTLS = "primary_tls"
+
+	ConfigKeyTLSEnabled    = "app.tls.enabled"
+	ConfigKeyTLSCert       = "app.tls.cert"
+	ConfigKeyTLSKey        = "app.tls.key"
+	ConfigKeyTLSMinVersion = "app.tls.minversion"
+	ConfigKeyTLSCiphers    = "app.tls.ciphers"
+	ConfigKeyTLSClientCA   = "app.tls.clientca"
+
+	// DefaultTLSMinVersion is used when no minimum version is configured
+	DefaultTLSMinVersion = "1.2"
+)
+
+// tlsVersions by config name
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsCiphers are the cipher suites that can be configured, by name. TLS 1.3 suites are not configurable
+var tlsCiphers = map[string]uint16{
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256":       tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384":       tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256": tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":         tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":         tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256":   tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+}
+
+// TLSReloader serves the TLS config of the server and reloads the certificate
+// and the client CA bundle when their files change on disk
+type TLSReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	base         *tls.Config
+	logger       *zap.Logger
+	stopWatch    func()
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// InitTLS - Initialize the server TLS config and store in container, only used when TLS is enabled
+func InitTLS(builder *di.Builder) (err error) {
+
+	err = builder.Add(
+		di.Def{
+			Name:  InstTLS,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				cfg := ctn.Get(InstConfig).(*AppConfig).TLS
+
+				return NewTLSReloader(
+					cfg.Cert,
+					cfg.Key,
+					cfg.ClientCA,
+					cfg.MinVersion,
+					cfg.Ciphers,
+					ctn.Get(InstWatcher).(*FileWatcher),
+					ctn.Get(InstLogger).(*zap.Logger),
+				)
+			},
+			Close: func(obj interface{}) error {
+				return obj.(*TLSReloader).Close()
+			},
+		})
+
+	return
+}
+
+// NewTLSReloader - Load the certificate and, when given, the client CA bundle which turns on mutual TLS,
+// and watch their files for changes with the watcher. Empty minVersion and ciphers use the defaults
+func NewTLSReloader(certFile string, keyFile string, clientCAFile string, minVersion string, ciphers []string, watcher *FileWatcher, logger *zap.Logger) (r *TLSReloader, err error) {
+	r = &TLSReloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		logger:       logger,
+	}
+
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("%s and %s are required when TLS is enabled", ConfigKeyTLSCert, ConfigKeyTLSKey)
+	}
+
+	if r.base, err = baseTLSConfig(minVersion, ciphers); err != nil {
+		return nil, err
+	}
+
+	if err = r.reload(); err != nil {
+		return nil, err
+	}
+
+	if err = r.watch(watcher); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Config - TLS config for the server, every handshake uses the files loaded last
+func (r *TLSReloader) Config() *tls.Config {
+	c := r.base.Clone()
+	c.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		return r.current(), nil
+	}
+
+	return c
+}
+
+// Close - Stop watching the files
+func (r *TLSReloader) Close() error {
+	r.stopWatch()
+
+	return nil
+}
+
+// current - TLS config with the files loaded last
+func (r *TLSReloader) current() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := r.base.Clone()
+	c.Certificates = []tls.Certificate{*r.cert}
+	if r.clientCAs != nil {
+		c.ClientCAs = r.clientCAs
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return c
+}
+
+// reload - Load the files, the loaded ones are kept when any of them is invalid
+func (r *TLSReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate-> %v", err)
+	}
+
+	var pool *x509.CertPool
+	if r.clientCAFile != "" {
+		if pool, err = LoadCertPool(r.clientCAFile); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCAs = &cert, pool
+	r.mu.Unlock()
+
+	return nil
+}
+
+// watch - Reload the files when one of them changes, which includes the symlink swaps of mounted secrets
+func (r *TLSReloader) watch(watcher *FileWatcher) (err error) {
+	r.stopWatch, err = watcher.Watch("TLS files", func() {
+		// a file can be caught half written, the next event of the write reloads it
+		if err := r.reload(); err != nil {
+			r.logger.Warn("Keeping the loaded TLS files", zap.Error(err))
+			return
+		}
+		r.logger.Info("Reloaded TLS files", zap.String("cert", r.certFile), zap.String("clientca", r.clientCAFile))
+	}, r.certFile, r.keyFile, r.clientCAFile)
+
+	return
+}
+
+// baseTLSConfig - TLS config with the protocol settings
+func baseTLSConfig(minVersion string, ciphers []string) (*tls.Config, error) {
+	if minVersion == "" {
+		minVersion = DefaultTLSMinVersion
+	}
+
+	version, ok := tlsVersions[minVersion]
+	if !ok {
+		return nil, fmt.Errorf("invalid %s '%s', should be 1.0, 1.1, 1.2 or 1.3", ConfigKeyTLSMinVersion, minVersion)
+	}
+
+	c := &tls.Config{
+		MinVersion: version,
+		NextProtos: []string{"h2"},
+	}
+
+	var invalid []string
+	for _, name := range ciphers {
+		if id, ok := tlsCiphers[name]; ok {
+			c.CipherSuites = append(c.CipherSuites, id)
+		} else {
+			invalid = append(invalid, name)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("invalid %s %s", ConfigKeyTLSCiphers, strings.Join(invalid, ", "))
+	}
+
+	return c, nil
+}
+
+// LoadCertPool - Load a PEM bundle of CA certificates
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CA bundle-> %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,147 @@
+package modules
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// writeCert writes a self signed certificate with the serial and its key into the directory
+func writeCert(t *testing.T, dir string, serial int64) (certFile string, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	// write the key first, the reload triggered by the key alone fails and keeps the previous pair
+	if err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+// serial of the certificate served for a handshake
+func serial(t *testing.T, c *tls.Config) int64 {
+	t.Helper()
+
+	current, err := c.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(current.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return leaf.SerialNumber.Int64()
+}
+
+func TestTLSReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir, 1)
+
+	watcher := testWatcher(t)
+	defer watcher.Close()
+
+	r, err := NewTLSReloader(certFile, keyFile, certFile, "1.3", nil, watcher, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewTLSReloader() error = %v", err)
+	}
+	defer r.Close()
+
+	c := r.Config()
+	if c.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Config() MinVersion = %x, want %x", c.MinVersion, tls.VersionTLS13)
+	}
+	if got := serial(t, c); got != 1 {
+		t.Fatalf("Config() serial = %d, want 1", got)
+	}
+	if current, _ := c.GetConfigForClient(&tls.ClientHelloInfo{}); current.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("Config() ClientAuth = %v, want mutual TLS", current.ClientAuth)
+	}
+
+	writeCert(t, dir, 2)
+
+	for deadline := time.Now().Add(5 * time.Second); serial(t, c) != 2; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Config() kept serving the old certificate")
+		}
+	}
+}
+
+func TestNewTLSReloader_invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir, 1)
+
+	watcher := testWatcher(t)
+	defer watcher.Close()
+
+	tests := []struct {
+		name       string
+		cert       string
+		minVersion string
+		ciphers    []string
+	}{
+		{name: "Missing certificate", cert: ""},
+		{name: "Unreadable certificate", cert: filepath.Join(dir, "missing.crt")},
+		{name: "Unknown version", cert: certFile, minVersion: "1.4"},
+		{name: "Unknown cipher", cert: certFile, ciphers: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r, err := NewTLSReloader(tt.cert, keyFile, "", tt.minVersion, tt.ciphers, watcher, zap.NewNop()); err == nil {
+				r.Close()
+				t.Errorf("NewTLSReloader() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterExporter - Writes every span as one JSON line, to stdout or a file so traces can be read offline
+type WriterExporter struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+// NewWriterExporter - Exporter writing to w, w is not closed by Close
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{enc: json.NewEncoder(w)}
+}
+
+// NewStdoutExporter - Exporter writing to the standard output
+func NewStdoutExporter() *WriterExporter {
+	return NewWriterExporter(os.Stdout)
+}
+
+// NewFileExporter - Exporter appending to the file, created when missing
+func NewFileExporter(file string) (*WriterExporter, error) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	e := NewWriterExporter(f)
+	e.closer = f
+
+	return e, nil
+}
+
+// ExportSpan - Write the span, a failed write drops it as tracing must not fail the call
+func (e *WriterExporter) ExportSpan(s SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_ = e.enc.Encode(s)
+}
+
+// Close - Close the file of the exporter
+func (e *WriterExporter) Close() error {
+	if e.closer == nil {
+		return nil
+	}
+
+	return e.closer.Close()
+}
+
+// RecordingExporter - Keeps the spans in memory, for tests
+type RecordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// ExportSpan - Keep the span
+func (e *RecordingExporter) ExportSpan(s SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, s)
+}
+
+// Close - Nothing to close
+func (e *RecordingExporter) Close() error {
+	return nil
+}
+
+// Spans - Spans exported so far, in the order they ended
+func (e *RecordingExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]SpanData(nil), e.spans...)
+}
@@ -0,0 +1,78 @@
+package trace
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MetadataKeyTraceParent carries the W3C trace context of the caller and is echoed in the response header
+	MetadataKeyTraceParent = "traceparent"
+)
+
+// UnaryServerInterceptor - Trace every unary call in a server span, child of the trace context sent by the caller
+func UnaryServerInterceptor(t *Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := t.startServer(ctx, info.FullMethod)
+		defer span.End()
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataKeyTraceParent, span.SpanContext().TraceParent()))
+
+		resp, err := handler(ctx, req)
+		endServer(span, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor - Trace every stream in a server span, child of the trace context sent by the caller
+func StreamServerInterceptor(t *Tracer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := t.startServer(ss.Context(), info.FullMethod)
+		defer span.End()
+
+		_ = ss.SetHeader(metadata.Pairs(MetadataKeyTraceParent, span.SpanContext().TraceParent()))
+
+		err := handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+		endServer(span, err)
+
+		return err
+	}
+}
+
+// startServer - Start the server span of the method, an invalid traceparent starts a new trace
+func (t *Tracer) startServer(ctx context.Context, method string) (context.Context, *Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataKeyTraceParent); len(values) > 0 {
+			if sc, err := ParseTraceParent(values[0]); err == nil {
+				ctx = WithRemoteParent(ctx, sc)
+			}
+		}
+	}
+
+	ctx, span := t.Start(ctx, method, KindServer)
+	span.SetAttribute("rpc.system", "grpc")
+	span.SetAttribute("rpc.method", method)
+
+	return ctx, span
+}
+
+// endServer - Record the status of the call
+func endServer(span *Span, err error) {
+	span.SetAttribute("rpc.grpc.status_code", status.Code(err).String())
+	span.SetError(err)
+}
+
+// tracedStream - Server stream carrying the context with the span
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context - Context of the stream with the span
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
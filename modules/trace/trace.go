@@ -0,0 +1,287 @@
+// Package trace records spans of the calls handled by the server and of the queries they run,
+// propagated with the W3C traceparent header and handed to an Exporter when they end
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// KindServer span of a call handled by the server
+	KindServer = "server"
+	// KindClient span of a call to another system, like a database
+	KindClient = "client"
+	// KindInternal span of work inside the server
+	KindInternal = "internal"
+
+	// StatusOK span ended without error
+	StatusOK = "OK"
+	// StatusError span ended with an error
+	StatusError = "ERROR"
+)
+
+// TraceID - Identifier of a trace
+type TraceID [16]byte
+
+// SpanID - Identifier of a span within a trace
+type SpanID [8]byte
+
+// String - Lower case hex
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// String - Lower case hex
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext - Part of a span propagated to other processes
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid - Whether both ids are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// TraceParent - W3C traceparent header value of the span context
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceParent - Span context of a W3C traceparent header value
+func ParseTraceParent(value string) (sc SpanContext, err error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, errors.New("invalid traceparent")
+	}
+
+	// a version 00 header has exactly four fields, later versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, errors.New("invalid traceparent")
+	}
+
+	var flags [1]byte
+	if _, err = hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, errors.New("invalid traceparent trace id")
+	}
+	if _, err = hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, errors.New("invalid traceparent span id")
+	}
+	if _, err = hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return sc, errors.New("invalid traceparent flags")
+	}
+
+	if !sc.IsValid() {
+		return sc, errors.New("invalid traceparent, zero id")
+	}
+	sc.Sampled = flags[0]&1 == 1
+
+	return sc, nil
+}
+
+// SpanData - Finished span as handed to the exporter
+type SpanData struct {
+	TraceID       string                 `json:"trace_id"`
+	SpanID        string                 `json:"span_id"`
+	ParentID      string                 `json:"parent_id,omitempty"`
+	Name          string                 `json:"name"`
+	Kind          string                 `json:"kind"`
+	Start         time.Time              `json:"start"`
+	End           time.Time              `json:"end"`
+	Duration      time.Duration          `json:"duration_ns"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Status        string                 `json:"status"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+}
+
+// Exporter - Receives the sampled spans when they end
+type Exporter interface {
+	ExportSpan(s SpanData)
+	Close() error
+}
+
+// Tracer - Starts spans and exports the sampled ones
+type Tracer struct {
+	exporter    Exporter
+	sampleRatio float64
+}
+
+// NewTracer - Tracer sampling the ratio of the traces it starts, 0 samples none and 1 every one.
+// Traces started by a caller keep the sampling decision of the caller
+func NewTracer(exporter Exporter, sampleRatio float64) *Tracer {
+	return &Tracer{exporter: exporter, sampleRatio: sampleRatio}
+}
+
+// Close - Close the exporter
+func (t *Tracer) Close() error {
+	return t.exporter.Close()
+}
+
+// Start - Start a span, child of the span of the context or of the remote parent stored with WithRemoteParent,
+// and the root of a new trace otherwise. The span is stored in the returned context
+func (t *Tracer) Start(ctx context.Context, name string, kind string) (context.Context, *Span) {
+	s := &Span{tracer: t, name: name, kind: kind, start: time.Now()}
+
+	if parent := FromContext(ctx); parent != nil {
+		s.sc.TraceID, s.sc.Sampled, s.parentID = parent.sc.TraceID, parent.sc.Sampled, parent.sc.SpanID
+	} else if remote, ok := ctx.Value(remoteParentKey{}).(SpanContext); ok && remote.IsValid() {
+		s.sc.TraceID, s.sc.Sampled, s.parentID = remote.TraceID, remote.Sampled, remote.SpanID
+	} else {
+		_, _ = rand.Read(s.sc.TraceID[:])
+		s.sc.Sampled = t.sample(s.sc.TraceID)
+	}
+	_, _ = rand.Read(s.sc.SpanID[:])
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// sample - Sampling decision of a new trace, derived from its id so it is stable for the trace
+func (t *Tracer) sample(id TraceID) bool {
+	if t.sampleRatio >= 1 {
+		return true
+	}
+	if t.sampleRatio <= 0 {
+		return false
+	}
+
+	var n uint64
+	for _, b := range id[8:] {
+		n = n<<8 | uint64(b)
+	}
+
+	return float64(n>>11)/(1<<53) < t.sampleRatio
+}
+
+// Span - Timed operation of a trace. A nil span is valid and records nothing,
+// so code can trace without checking whether tracing is enabled
+type Span struct {
+	tracer   *Tracer
+	sc       SpanContext
+	parentID SpanID
+	name     string
+	kind     string
+	start    time.Time
+
+	mu         sync.Mutex
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+// SpanContext - Ids of the span, zero for a nil span
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.sc
+}
+
+// SetAttribute - Record a value describing the operation
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attributes == nil {
+		s.attributes = map[string]interface{}{}
+	}
+	s.attributes[key] = value
+}
+
+// SetError - Mark the span as failed, a nil error leaves it unchanged
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// End - Finish the span and export it when sampled, later calls do nothing
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+
+	end := time.Now()
+	data := SpanData{
+		TraceID:    s.sc.TraceID.String(),
+		SpanID:     s.sc.SpanID.String(),
+		Name:       s.name,
+		Kind:       s.kind,
+		Start:      s.start,
+		End:        end,
+		Duration:   end.Sub(s.start),
+		Attributes: s.attributes,
+		Status:     StatusOK,
+	}
+	if s.parentID != (SpanID{}) {
+		data.ParentID = s.parentID.String()
+	}
+	if s.err != nil {
+		data.Status, data.StatusMessage = StatusError, s.err.Error()
+	}
+	s.mu.Unlock()
+
+	if s.sc.Sampled {
+		s.tracer.exporter.ExportSpan(data)
+	}
+}
+
+// spanKey is the context key of the current span
+type spanKey struct{}
+
+// remoteParentKey is the context key of the span context received from the caller
+type remoteParentKey struct{}
+
+// FromContext - Current span of the context, nil when there is none
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// WithRemoteParent - Store the span context received from the caller, the next span started is its child
+func WithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteParentKey{}, sc)
+}
+
+// Start - Start a child of the span of the context with the same tracer.
+// Without a span in the context nothing is traced and the returned span is nil
+func Start(ctx context.Context, name string, kind string) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+
+	return parent.tracer.Start(ctx, name, kind)
+}
@@ -0,0 +1,105 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantSampled bool
+		wantErr     bool
+	}{
+		{name: "Sampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantSampled: true},
+		{name: "Not sampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{name: "Future version with more fields", value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantSampled: true},
+		{name: "Zero trace id", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{name: "Invalid version", value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "Not hex", value: "00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01", wantErr: true},
+		{name: "Short", value: "00-4bf92f3577b34da6-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceParent(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTraceParent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if sc.Sampled != tt.wantSampled {
+				t.Errorf("ParseTraceParent() sampled = %v, want %v", sc.Sampled, tt.wantSampled)
+			}
+			if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+				t.Errorf("ParseTraceParent() = %s", sc.TraceParent())
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	exporter := &RecordingExporter{}
+	tracer := NewTracer(exporter, 0)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, span := Start(ctx, "child", KindInternal)
+		span.End()
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	// the caller sampled the trace, so it is exported although the tracer samples none of its own
+	md := metadata.Pairs(MetadataKeyTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	_, _ = UnaryServerInterceptor(tracer)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/todo.ToDoService/Read"}, handler)
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+
+	child, server := spans[0], spans[1]
+	if server.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentID != "00f067aa0ba902b7" {
+		t.Errorf("server span = %+v, want child of the caller", server)
+	}
+	if child.TraceID != server.TraceID || child.ParentID != server.SpanID {
+		t.Errorf("child span = %+v, want child of the server span", child)
+	}
+	if server.Status != StatusError || server.Attributes["rpc.grpc.status_code"] != "NotFound" {
+		t.Errorf("server span status = %s %v, want the NotFound error", server.Status, server.Attributes)
+	}
+
+	// a trace of its own is not sampled
+	_, _ = UnaryServerInterceptor(tracer)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/todo.ToDoService/Read"}, handler)
+	if n := len(exporter.Spans()); n != 2 {
+		t.Errorf("exported %d spans, want the unsampled trace dropped", n)
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer(NewWriterExporter(&buf), 1)
+
+	_, span := tracer.Start(context.Background(), "op", KindInternal)
+	span.SetAttribute("key", "value")
+	span.SetError(errors.New("failed"))
+	span.End()
+	span.End()
+
+	var data SpanData
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		t.Fatalf("exporter wrote %q, want one JSON span-> %v", buf.String(), err)
+	}
+	if data.Name != "op" || data.Status != StatusError || data.StatusMessage != "failed" || data.Attributes["key"] != "value" {
+		t.Errorf("exported span = %+v", data)
+	}
+}
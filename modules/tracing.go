@@ -0,0 +1,56 @@
+package modules
+
+import (
+	"fmt"
+
+	"github.com/sarulabs/di"
+	"grpoc/modules/trace"
+)
+
+const (
+	InstTracer = "primary_tracer"
+
+	ConfigKeyTracingExporter    = "app.tracing.exporter"
+	ConfigKeyTracingFile        = "app.tracing.file"
+	ConfigKeyTracingSampleRatio = "app.tracing.sampleratio"
+
+	// Span exporters
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+)
+
+// InitTracing - Initialize the tracer with the configured exporter and store in container, only used when the tracing interceptor is enabled
+func InitTracing(builder *di.Builder) (err error) {
+
+	err = builder.Add(
+		di.Def{
+			Name:  InstTracer,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				var (
+					cfg      = ctn.Get(InstConfig).(*AppConfig).Tracing
+					exporter trace.Exporter
+				)
+
+				switch name := cfg.Exporter; name {
+				case TracingExporterStdout, "":
+					exporter = trace.NewStdoutExporter()
+				case TracingExporterFile:
+					if exporter, e = trace.NewFileExporter(cfg.File); e != nil {
+						e = fmt.Errorf("failed to open %s-> %v", ConfigKeyTracingFile, e)
+						return
+					}
+				default:
+					e = fmt.Errorf("unknown %s '%s', should be %s or %s", ConfigKeyTracingExporter, name, TracingExporterStdout, TracingExporterFile)
+					return
+				}
+
+				return trace.NewTracer(exporter, cfg.SampleRatio), nil
+			},
+			Close: func(obj interface{}) error {
+				return obj.(*trace.Tracer).Close()
+			},
+		})
+
+	return
+}
@@ -0,0 +1,201 @@
+package modules
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sarulabs/di"
+	"go.uber.org/zap"
+)
+
+const (
+	InstWatcher = "primary_watcher"
+
+	// configMapData is the symlink a mounted ConfigMap or secret swaps to publish a new version of its files
+	configMapData = "..data"
+)
+
+// FileWatcher - One fsnotify watcher shared by the reloads of the config, the policy and the TLS files.
+// The directories of the files are watched, an event only goes to the owners of the file it names
+type FileWatcher struct {
+	logger  *zap.Logger
+	watcher *fsnotify.Watcher
+
+	// mu guards the directories and the watches
+	mu      sync.Mutex
+	dirs    map[string]int
+	watches map[*fileWatch]bool
+}
+
+// fileWatch - Files of one owner, onChange is called on their events
+type fileWatch struct {
+	what     string
+	files    []string
+	onChange func()
+}
+
+// InitWatcher - Initialize the file watcher shared by the reloads and store in container
+func InitWatcher(builder *di.Builder) (err error) {
+
+	err = builder.Add(
+		di.Def{
+			Name:  InstWatcher,
+			Scope: di.App,
+			Build: func(ctn di.Container) (i interface{}, e error) {
+				return NewFileWatcher(ctn.Get(InstLogger).(*zap.Logger))
+			},
+			Close: func(obj interface{}) error {
+				return obj.(*FileWatcher).Close()
+			},
+		})
+
+	return
+}
+
+// NewFileWatcher - Start watching, nothing is watched until Watch is called
+func NewFileWatcher(logger *zap.Logger) (w *FileWatcher, err error) {
+	w = &FileWatcher{
+		logger:  logger,
+		dirs:    make(map[string]int),
+		watches: make(map[*fileWatch]bool),
+	}
+
+	if w.watcher, err = fsnotify.NewWatcher(); err != nil {
+		return nil, err
+	}
+
+	go w.run()
+
+	return
+}
+
+// Watch - Call onChange on the events of the files until stop is called, empty names are skipped.
+// Editors replacing a file create it again, and the ..data symlink swap of a mounted ConfigMap
+// or secret changes every file of its directory. onChange loads the files again and tells from their
+// content whether they changed. what names the files in the logs
+func (w *FileWatcher) Watch(what string, onChange func(), files ...string) (stop func(), err error) {
+	watch := &fileWatch{what: what, onChange: onChange}
+
+	for _, f := range files {
+		if f == "" {
+			continue
+		}
+		// the events are named after the watched directory, one spelling of the path matches them all
+		if f, err = filepath.Abs(f); err != nil {
+			return nil, err
+		}
+		watch.files = append(watch.files, f)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var added []string
+	for _, dir := range watch.dirs() {
+		if w.dirs[dir] == 0 {
+			if err = w.watcher.Add(dir); err != nil {
+				for _, d := range added {
+					w.removeDir(d)
+				}
+				return nil, err
+			}
+		}
+		w.dirs[dir]++
+		added = append(added, dir)
+	}
+	w.watches[watch] = true
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+
+			delete(w.watches, watch)
+			for _, dir := range watch.dirs() {
+				w.removeDir(dir)
+			}
+		})
+	}
+
+	return
+}
+
+// Close - Stop watching, the watches are dropped
+func (w *FileWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// removeDir - Stop watching the directory once no watch has a file in it, mu is held
+func (w *FileWatcher) removeDir(dir string) {
+	if w.dirs[dir]--; w.dirs[dir] > 0 {
+		return
+	}
+
+	delete(w.dirs, dir)
+	// fails once the watcher is closed, nothing is watched anymore then
+	_ = w.watcher.Remove(dir)
+}
+
+// run - Hand the events to the watches of their file until the watcher is closed
+func (w *FileWatcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			// the owners are called without the lock, they may stop their watch
+			for _, watch := range w.match(event.Name) {
+				w.logger.Debug("Watched file changed", zap.String("files", watch.what), zap.Stringer("event", event))
+				watch.onChange()
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error("Watching files failed", zap.Error(err))
+		}
+	}
+}
+
+// match - Watches having the file the event names
+func (w *FileWatcher) match(name string) (watches []*fileWatch) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for watch := range w.watches {
+		if watch.matches(name) {
+			watches = append(watches, watch)
+		}
+	}
+
+	return
+}
+
+// dirs - Directories of the files, once each
+func (watch *fileWatch) dirs() (dirs []string) {
+	seen := make(map[string]bool, len(watch.files))
+	for _, f := range watch.files {
+		if dir := filepath.Dir(f); !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return
+}
+
+// matches - Whether the event of the path concerns one of the files, the file itself or the ..data symlink of its directory
+func (watch *fileWatch) matches(name string) bool {
+	name = filepath.Clean(name)
+
+	for _, f := range watch.files {
+		if name == f || name == filepath.Join(filepath.Dir(f), configMapData) {
+			return true
+		}
+	}
+
+	return false
+}
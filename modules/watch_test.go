@@ -0,0 +1,129 @@
+package modules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testWatcher - File watcher the caller closes
+func testWatcher(t *testing.T) *FileWatcher {
+	t.Helper()
+
+	watcher, err := NewFileWatcher(zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return watcher
+}
+
+// waitEvent - Fail unless the channel gets an event in time
+func waitEvent(t *testing.T, events chan struct{}, what string) {
+	t.Helper()
+
+	select {
+	case <-events:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Watch() did not report %s", what)
+	}
+}
+
+// noEvent - Fail when the channel gets an event in a while
+func noEvent(t *testing.T, events chan struct{}, what string) {
+	t.Helper()
+
+	select {
+	case <-events:
+		t.Fatalf("Watch() reported %s", what)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestFileWatcher_Watch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	watcher := testWatcher(t)
+	defer watcher.Close()
+
+	// the config and the policy share the directory and the watcher
+	config, policy := make(chan struct{}, 16), make(chan struct{}, 16)
+	stopConfig, err := watcher.Watch("config", func() { config <- struct{}{} }, filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer stopConfig()
+	stopPolicy, err := watcher.Watch("policy", func() { policy <- struct{}{} }, filepath.Join(dir, "policy.yaml"), "")
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	writePolicy(t, dir, testPolicy)
+	waitEvent(t, policy, "the policy write")
+	noEvent(t, config, "the policy write to the config")
+
+	writeConfig(t, dir, "app:\n  port: 3000\n")
+	waitEvent(t, config, "the config write")
+	// the events come in order, the ones of the policy write were all handed out
+	for len(policy) > 0 {
+		<-policy
+	}
+
+	// the directory stays watched for the config
+	stopPolicy()
+	writePolicy(t, dir, testPolicy)
+	writeConfig(t, dir, "app:\n  port: 4000\n")
+	waitEvent(t, config, "the config write once the policy is not watched")
+	noEvent(t, policy, "the policy write once its watch stopped")
+}
+
+func TestFileWatcher_configMap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// layout of a mounted ConfigMap, policy.yaml -> ..data/policy.yaml and ..data -> the current version
+	for _, v := range []string{"..v1", "..v2"} {
+		if err = os.Mkdir(filepath.Join(dir, v), 0700); err != nil {
+			t.Fatal(err)
+		}
+		writePolicy(t, filepath.Join(dir, v), testPolicy)
+	}
+	if err = os.Symlink("..v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "policy.yaml")
+	if err = os.Symlink(filepath.Join("..data", "policy.yaml"), file); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := testWatcher(t)
+	defer watcher.Close()
+
+	events := make(chan struct{}, 16)
+	stop, err := watcher.Watch("policy", func() { events <- struct{}{} }, file)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer stop()
+
+	// the kubelet renames a new symlink over ..data, no event names policy.yaml
+	if err = os.Symlink("..v2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+
+	waitEvent(t, events, "the ..data symlink swap")
+}
@@ -0,0 +1,28 @@
+package todo
+
+import (
+	"github.com/sarulabs/di"
+	"google.golang.org/grpc"
+	"grpoc/app"
+)
+
+// ServiceName is the name of the ToDo service in the config, app.services.todo
+const ServiceName = "todo"
+
+// Service is the ToDo service as an app plugin
+type Service struct{}
+
+// the program serving the ToDo service imports the package, like a database/sql driver
+func init() {
+	app.RegisterService(Service{})
+}
+
+// Name of the service in the config
+func (Service) Name() string {
+	return ServiceName
+}
+
+// Register the ToDo service on the server, it uses the database and the logger of the container
+func (Service) Register(server *grpc.Server, container di.Container) {
+	RegisterToDoServiceServer(server, NewToDoServiceServer(&container))
+}
@@ -0,0 +1,17 @@
+package todo
+
+import (
+	"testing"
+
+	"grpoc/app"
+)
+
+func TestService_registered(t *testing.T) {
+	for _, s := range app.DefaultRegistry.Services() {
+		if s.Name() == ServiceName {
+			return
+		}
+	}
+
+	t.Errorf("%s is not in app.DefaultRegistry, the package registers it from init", ServiceName)
+}
@@ -2,11 +2,14 @@ package todo
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/sarulabs/di"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"grpoc/models"
@@ -21,13 +24,34 @@ const (
 
 // toDoServiceServer is implementation of v1.ToDoServiceServer proto interface
 type toDoServiceServer struct {
-	db *sql.DB
+	db           *sql.DB
+	logger       *zap.Logger
+	pageTokenKey []byte
 }
 
 // NewToDoServiceServer creates ToDo service
 func NewToDoServiceServer(cont *di.Container) ToDoServiceServer {
 	db := (*cont).Get(modules.InstDatabase).(*sql.DB)
-	return &toDoServiceServer{db: db}
+	logger := (*cont).Get(modules.InstLogger).(*zap.Logger)
+	return &toDoServiceServer{db: db, logger: logger, pageTokenKey: pageTokenKey(cont, logger)}
+}
+
+// pageTokenKey returns the configured page token secret.
+// Without one a random key is used, so tokens only stay valid for the lifetime of this server
+func pageTokenKey(cont *di.Container, logger *zap.Logger) []byte {
+	if c, err := (*cont).SafeGet(modules.InstConfig); err == nil {
+		if secret := c.(*modules.AppConfig).Pagination.Secret; secret != "" {
+			return []byte(secret)
+		}
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		logger.Fatal("Failed to generate page token key", zap.Error(err))
+	}
+	logger.Warn("No page token secret configured in app.pagination.secret, page tokens will not survive a restart")
+
+	return key
 }
 
 // checkAPI checks if the API version requested by client is supported by server
@@ -51,6 +75,39 @@ func (s *toDoServiceServer) connect(ctx context.Context) (*sql.Conn, error) {
 	return c, nil
 }
 
+// runInTx runs fn with the todo model bound to a transaction, so its steps are all-or-nothing.
+// Database errors must be returned as is for deadlocks to be retried, they are reported through dbError
+// prefixed with failure, status errors returned by fn are passed through
+func (s *toDoServiceServer) runInTx(ctx context.Context, todoModel *models.ToDo, failure string, fn func(tx *models.ToDo) error) error {
+	err := todoModel.RunInTx(ctx, nil, func(tx *mymodel.Model) error {
+		return fn(todoModel.WithTx(tx))
+	})
+
+	if _, ok := status.FromError(err); !ok {
+		return s.dbError(ctx, failure, err)
+	}
+
+	return err
+}
+
+// dbError logs a database error and converts it into a status error.
+// A query aborted because the request was cancelled or ran out of time keeps the code of the request,
+// a call reaching the models without the tenant of an authenticated caller is codes.Unauthenticated
+func (s *toDoServiceServer) dbError(ctx context.Context, failure string, err error) error {
+	modules.Logger(ctx, s.logger).Error(failure, zap.Error(err))
+
+	switch {
+	case err.Error() == mymodel.NoTenantInContext:
+		return status.Error(codes.Unauthenticated, failure+"-> "+err.Error())
+	case err == context.DeadlineExceeded || ctx.Err() == context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, failure+"-> "+err.Error())
+	case err == context.Canceled || ctx.Err() == context.Canceled:
+		return status.Error(codes.Canceled, failure+"-> "+err.Error())
+	}
+
+	return status.Error(codes.Unknown, failure+"-> "+err.Error())
+}
+
 // Create new todo task
 func (s *toDoServiceServer) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
 	var (
@@ -65,21 +122,30 @@ func (s *toDoServiceServer) Create(ctx context.Context, req *CreateRequest) (*Cr
 		return nil, err
 	}
 
+	if req.ToDo == nil {
+		return nil, status.Error(codes.InvalidArgument, "toDo field is required")
+	}
+
 	reminder, err = ptypes.Timestamp(req.ToDo.Reminder)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, "reminder field has invalid format-> "+err.Error())
 	}
 
-	// insert ToDo entity data
-	res, err = todoModel.AddTodo(req.ToDo.Title, req.ToDo.Description, reminder)
-	if err != nil {
-		return nil, status.Error(codes.Unknown, "failed to insert into ToDo-> "+err.Error())
-	}
+	// insert ToDo entity data, further steps of the creation join the same transaction
+	err = s.runInTx(ctx, todoModel, "failed to insert into ToDo", func(tx *models.ToDo) (e error) {
+		if res, e = tx.AddTodo(ctx, req.ToDo.Title, req.ToDo.Description, reminder); e != nil {
+			return
+		}
 
-	// get ID of creates ToDo
-	id, err = res.LastInsertId()
+		// get ID of creates ToDo
+		if id, e = res.LastInsertId(); e != nil {
+			return status.Error(codes.Unknown, "failed to retrieve id for created ToDo-> "+e.Error())
+		}
+
+		return
+	})
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "failed to retrieve id for created ToDo-> "+err.Error())
+		return nil, err
 	}
 
 	return &CreateResponse{
@@ -92,7 +158,6 @@ func (s *toDoServiceServer) Create(ctx context.Context, req *CreateRequest) (*Cr
 func (s *toDoServiceServer) Read(ctx context.Context, req *ReadRequest) (*ReadResponse, error) {
 	var (
 		todoModel, _ = models.NewToDo(ctx, s.db)
-		td           ToDo
 	)
 	// check if the API version requested by client is supported by server
 	if err := s.checkAPI(req.Api); err != nil {
@@ -100,20 +165,215 @@ func (s *toDoServiceServer) Read(ctx context.Context, req *ReadRequest) (*ReadRe
 	}
 
 	// query ToDo by ID
-	todo, err := todoModel.GetTodoByID(req.Id)
+	todo, err := todoModel.GetTodoByID(ctx, req.Id)
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "ToDo with ID='%d' is not found", req.Id)
+	}
+	if err != nil {
+		return nil, s.dbError(ctx, "failed to select from ToDo", err)
+	}
+
+	return &ReadResponse{
+		Api:  apiVersion,
+		ToDo: toProto(todo),
+	}, nil
+
+}
+
+// Update todo task
+func (s *toDoServiceServer) Update(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
+	var (
+		err          error
+		todoModel, _ = models.NewToDo(ctx, s.db)
+		reminder     time.Time
+		rows         int64
+		res          sql.Result
+	)
+	// check if the API version requested by client is supported by server
+	if err = s.checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	if req.ToDo == nil {
+		return nil, status.Error(codes.InvalidArgument, "toDo field is required")
+	}
+
+	reminder, err = ptypes.Timestamp(req.ToDo.Reminder)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "reminder field has invalid format-> "+err.Error())
+	}
+
+	// update ToDo
+	res, err = todoModel.UpdateTodo(ctx, req.ToDo.Id, req.ToDo.Title, req.ToDo.Description, reminder)
+	if err != nil {
+		return nil, s.dbError(ctx, "failed to update ToDo", err)
+	}
+
+	rows, err = res.RowsAffected()
+	if err != nil {
+		return nil, status.Error(codes.Unknown, "failed to retrieve rows affected value-> "+err.Error())
+	}
+
+	// without clientFoundRows an update leaving the row as it is affects no row, only a missing row is not found
+	if rows == 0 {
+		_, err = todoModel.GetTodoByID(ctx, req.ToDo.Id)
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "ToDo with ID='%d' is not found", req.ToDo.Id)
+		}
+		if err != nil {
+			return nil, s.dbError(ctx, "failed to select from ToDo", err)
+		}
+		rows = 1
+	}
+
+	return &UpdateResponse{
+		Api:     apiVersion,
+		Updated: rows,
+	}, nil
+}
+
+// Delete todo task
+func (s *toDoServiceServer) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	var (
+		err          error
+		todoModel, _ = models.NewToDo(ctx, s.db)
+		rows         int64
+		res          sql.Result
+	)
+	// check if the API version requested by client is supported by server
+	if err = s.checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// delete ToDo
+	res, err = todoModel.DeleteTodo(ctx, req.Id)
+	if err != nil {
+		return nil, s.dbError(ctx, "failed to delete ToDo", err)
+	}
+
+	rows, err = res.RowsAffected()
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "failed to select from ToDo-> "+err.Error())
+		return nil, status.Error(codes.Unknown, "failed to retrieve rows affected value-> "+err.Error())
+	}
+
+	if rows == 0 {
+		return nil, status.Errorf(codes.NotFound, "ToDo with ID='%d' is not found", req.Id)
 	}
 
+	return &DeleteResponse{
+		Api:     apiVersion,
+		Deleted: rows,
+	}, nil
+}
+
+// ReadAll todo tasks
+func (s *toDoServiceServer) ReadAll(ctx context.Context, req *ReadAllRequest) (*ReadAllResponse, error) {
+	var (
+		todoModel, _ = models.NewToDo(ctx, s.db)
+		list         = []*ToDo{}
+	)
+	// check if the API version requested by client is supported by server
+	if err := s.checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// get ToDo list
+	todos, err := todoModel.GetTodos(ctx)
+	if err != nil {
+		return nil, s.dbError(ctx, "failed to select from ToDo", err)
+	}
+
+	for _, todo := range todos {
+		list = append(list, toProto(todo))
+	}
+
+	return &ReadAllResponse{
+		Api:   apiVersion,
+		ToDos: list,
+	}, nil
+}
+
+// ListToDos lists todo tasks page by page
+func (s *toDoServiceServer) ListToDos(ctx context.Context, req *ListToDosRequest) (*ListToDosResponse, error) {
+	var (
+		err          error
+		todoModel, _ = models.NewToDo(ctx, s.db)
+		filter       models.ToDoFilter
+		after        *models.ToDoCursor
+		list         = []*ToDo{}
+		token        string
+	)
+	// check if the API version requested by client is supported by server
+	if err = s.checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	size := int(req.PageSize)
+	if size < 0 {
+		return nil, status.Error(codes.InvalidArgument, "pageSize must not be negative")
+	}
+	if size == 0 || size > mymodel.MaxLimit {
+		size = mymodel.MaxLimit
+	}
+
+	filter.TitleContains = req.TitleContains
+	if req.ReminderBefore != nil {
+		if filter.ReminderBefore, err = ptypes.Timestamp(req.ReminderBefore); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "reminderBefore field has invalid format-> "+err.Error())
+		}
+	}
+	if req.ReminderAfter != nil {
+		if filter.ReminderAfter, err = ptypes.Timestamp(req.ReminderAfter); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "reminderAfter field has invalid format-> "+err.Error())
+		}
+	}
+
+	if req.PageToken != "" {
+		after = &models.ToDoCursor{}
+		if err = mymodel.DecodePageToken(s.pageTokenKey, req.PageToken, after); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	// get ToDo page
+	todos, next, err := todoModel.ListTodos(ctx, filter, after, size)
+	if err != nil && err.Error() == mymodel.InvalidPageToken {
+		return nil, status.Error(codes.InvalidArgument, "page token does not match the request filters")
+	}
+	if errors.Is(err, models.ErrInvalidReminder) {
+		modules.Logger(ctx, s.logger).Error("failed to issue the next page token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to issue the next page token-> "+err.Error())
+	}
+	if err != nil {
+		return nil, s.dbError(ctx, "failed to select from ToDo", err)
+	}
+
+	for _, todo := range todos {
+		list = append(list, toProto(todo))
+	}
+
+	if next != nil {
+		if token, err = mymodel.EncodePageToken(s.pageTokenKey, next); err != nil {
+			return nil, status.Error(codes.Internal, "failed to create page token-> "+err.Error())
+		}
+	}
+
+	return &ListToDosResponse{
+		Api:           apiVersion,
+		ToDos:         list,
+		NextPageToken: token,
+	}, nil
+}
+
+// toProto converts the ToDo model into its protobuf message
+func toProto(todo models.ToDo) *ToDo {
+	var td ToDo
+
 	td.Id = todo.ID
 	td.Description = todo.Description
 	td.Title = todo.Title
-	rem, _ := time.Parse(mymodel.SQLDatetime, todo.Reminder)
+	rem, _ := mymodel.ParseDatetime(todo.Reminder)
 	td.Reminder, _ = ptypes.TimestampProto(rem)
 
-	return &ReadResponse{
-		Api:  apiVersion,
-		ToDo: &td,
-	}, nil
-
+	return &td
 }
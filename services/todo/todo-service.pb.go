@@ -276,37 +276,468 @@ func (m *ReadResponse) GetToDo() *ToDo {
 	return nil
 }
 
+type UpdateRequest struct {
+	Api                  string   `protobuf:"bytes,1,opt,name=api,proto3" json:"api,omitempty"`
+	ToDo                 *ToDo    `protobuf:"bytes,2,opt,name=toDo,proto3" json:"toDo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateRequest) Reset()         { *m = UpdateRequest{} }
+func (m *UpdateRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateRequest) ProtoMessage()    {}
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_80b701c7b1c502fe, []int{5}
+}
+
+func (m *UpdateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateRequest.Unmarshal(m, b)
+}
+func (m *UpdateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateRequest.Merge(m, src)
+}
+func (m *UpdateRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateRequest.Size(m)
+}
+func (m *UpdateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateRequest proto.InternalMessageInfo
+
+func (m *UpdateRequest) GetApi() string {
+	if m != nil {
+		return m.Api
+	}
+	return ""
+}
+
+func (m *UpdateRequest) GetToDo() *ToDo {
+	if m != nil {
+		return m.ToDo
+	}
+	return nil
+}
+
+type UpdateResponse struct {
+	Api                  string   `protobuf:"bytes,1,opt,name=api,proto3" json:"api,omitempty"`
+	Updated              int64    `protobuf:"varint,2,opt,name=updated,proto3" json:"updated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateResponse) Reset()         { *m = UpdateResponse{} }
+func (m *UpdateResponse) String() string { return proto.CompactTextString(m) }
+func (*UpdateResponse) ProtoMessage()    {}
+func (*UpdateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_80b701c7b1c502fe, []int{6}
+}
+
+func (m *UpdateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateResponse.Unmarshal(m, b)
+}
+func (m *UpdateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateResponse.Marshal(b, m, deterministic)
+}
+func (m *UpdateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateResponse.Merge(m, src)
+}
+func (m *UpdateResponse) XXX_Size() int {
+	return xxx_messageInfo_UpdateResponse.Size(m)
+}
+func (m *UpdateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateResponse proto.InternalMessageInfo
+
+func (m *UpdateResponse) GetApi() string {
+	if m != nil {
+		return m.Api
+	}
+	return ""
+}
+
+func (m *UpdateResponse) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
+type DeleteRequest struct {
+	Api                  string   `protobuf:"bytes,1,opt,name=api,proto3" json:"api,omitempty"`
+	Id                   int64    `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()    {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_80b701c7b1c502fe, []int{7}
+}
+
+func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
+}
+func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRequest.Merge(m, src)
+}
+func (m *DeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRequest.Size(m)
+}
+func (m *DeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
+
+func (m *DeleteRequest) GetApi() string {
+	if m != nil {
+		return m.Api
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type DeleteResponse struct {
+	Api                  string   `protobuf:"bytes,1,opt,name=api,proto3" json:"api,omitempty"`
+	Deleted              int64    `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteResponse) Reset()         { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()    {}
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_80b701c7b1c502fe, []int{8}
+}
+
+func (m *DeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteResponse.Unmarshal(m, b)
+}
+func (m *DeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteResponse.Merge(m, src)
+}
+func (m *DeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteResponse.Size(m)
+}
+func (m *DeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteResponse proto.InternalMessageInfo
+
+func (m *DeleteResponse) GetApi() string {
+	if m != nil {
+		return m.Api
+	}
+	return ""
+}
+
+func (m *DeleteResponse) GetDeleted() int64 {
+	if m != nil {
+		return m.Deleted
+	}
+	return 0
+}
+
+type ReadAllRequest struct {
+	Api                  string   `protobuf:"bytes,1,opt,name=api,proto3" json:"api,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadAllRequest) Reset()         { *m = ReadAllRequest{} }
+func (m *ReadAllRequest) String() string { return proto.CompactTextString(m) }
+func (*ReadAllRequest) ProtoMessage()    {}
+func (*ReadAllRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_80b701c7b1c502fe, []int{9}
+}
+
+func (m *ReadAllRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadAllRequest.Unmarshal(m, b)
+}
+func (m *ReadAllRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadAllRequest.Marshal(b, m, deterministic)
+}
+func (m *ReadAllRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadAllRequest.Merge(m, src)
+}
+func (m *ReadAllRequest) XXX_Size() int {
+	return xxx_messageInfo_ReadAllRequest.Size(m)
+}
+func (m *ReadAllRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadAllRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadAllRequest proto.InternalMessageInfo
+
+func (m *ReadAllRequest) GetApi() string {
+	if m != nil {
+		return m.Api
+	}
+	return ""
+}
+
+type ReadAllResponse struct {
+	Api                  string   `protobuf:"bytes,1,opt,name=api,proto3" json:"api,omitempty"`
+	ToDos                []*ToDo  `protobuf:"bytes,2,rep,name=toDos,proto3" json:"toDos,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadAllResponse) Reset()         { *m = ReadAllResponse{} }
+func (m *ReadAllResponse) String() string { return proto.CompactTextString(m) }
+func (*ReadAllResponse) ProtoMessage()    {}
+func (*ReadAllResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_80b701c7b1c502fe, []int{10}
+}
+
+func (m *ReadAllResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadAllResponse.Unmarshal(m, b)
+}
+func (m *ReadAllResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadAllResponse.Marshal(b, m, deterministic)
+}
+func (m *ReadAllResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadAllResponse.Merge(m, src)
+}
+func (m *ReadAllResponse) XXX_Size() int {
+	return xxx_messageInfo_ReadAllResponse.Size(m)
+}
+func (m *ReadAllResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadAllResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadAllResponse proto.InternalMessageInfo
+
+func (m *ReadAllResponse) GetApi() string {
+	if m != nil {
+		return m.Api
+	}
+	return ""
+}
+
+func (m *ReadAllResponse) GetToDos() []*ToDo {
+	if m != nil {
+		return m.ToDos
+	}
+	return nil
+}
+
+type ListToDosRequest struct {
+	Api                  string               `protobuf:"bytes,1,opt,name=api,proto3" json:"api,omitempty"`
+	PageSize             int32                `protobuf:"varint,2,opt,name=pageSize,proto3" json:"pageSize,omitempty"`
+	PageToken            string               `protobuf:"bytes,3,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
+	TitleContains        string               `protobuf:"bytes,4,opt,name=titleContains,proto3" json:"titleContains,omitempty"`
+	ReminderBefore       *timestamp.Timestamp `protobuf:"bytes,5,opt,name=reminderBefore,proto3" json:"reminderBefore,omitempty"`
+	ReminderAfter        *timestamp.Timestamp `protobuf:"bytes,6,opt,name=reminderAfter,proto3" json:"reminderAfter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ListToDosRequest) Reset()         { *m = ListToDosRequest{} }
+func (m *ListToDosRequest) String() string { return proto.CompactTextString(m) }
+func (*ListToDosRequest) ProtoMessage()    {}
+func (*ListToDosRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_80b701c7b1c502fe, []int{11}
+}
+
+func (m *ListToDosRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListToDosRequest.Unmarshal(m, b)
+}
+func (m *ListToDosRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListToDosRequest.Marshal(b, m, deterministic)
+}
+func (m *ListToDosRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListToDosRequest.Merge(m, src)
+}
+func (m *ListToDosRequest) XXX_Size() int {
+	return xxx_messageInfo_ListToDosRequest.Size(m)
+}
+func (m *ListToDosRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListToDosRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListToDosRequest proto.InternalMessageInfo
+
+func (m *ListToDosRequest) GetApi() string {
+	if m != nil {
+		return m.Api
+	}
+	return ""
+}
+
+func (m *ListToDosRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListToDosRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *ListToDosRequest) GetTitleContains() string {
+	if m != nil {
+		return m.TitleContains
+	}
+	return ""
+}
+
+func (m *ListToDosRequest) GetReminderBefore() *timestamp.Timestamp {
+	if m != nil {
+		return m.ReminderBefore
+	}
+	return nil
+}
+
+func (m *ListToDosRequest) GetReminderAfter() *timestamp.Timestamp {
+	if m != nil {
+		return m.ReminderAfter
+	}
+	return nil
+}
+
+type ListToDosResponse struct {
+	Api                  string   `protobuf:"bytes,1,opt,name=api,proto3" json:"api,omitempty"`
+	ToDos                []*ToDo  `protobuf:"bytes,2,rep,name=toDos,proto3" json:"toDos,omitempty"`
+	NextPageToken        string   `protobuf:"bytes,3,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListToDosResponse) Reset()         { *m = ListToDosResponse{} }
+func (m *ListToDosResponse) String() string { return proto.CompactTextString(m) }
+func (*ListToDosResponse) ProtoMessage()    {}
+func (*ListToDosResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_80b701c7b1c502fe, []int{12}
+}
+
+func (m *ListToDosResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListToDosResponse.Unmarshal(m, b)
+}
+func (m *ListToDosResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListToDosResponse.Marshal(b, m, deterministic)
+}
+func (m *ListToDosResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListToDosResponse.Merge(m, src)
+}
+func (m *ListToDosResponse) XXX_Size() int {
+	return xxx_messageInfo_ListToDosResponse.Size(m)
+}
+func (m *ListToDosResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListToDosResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListToDosResponse proto.InternalMessageInfo
+
+func (m *ListToDosResponse) GetApi() string {
+	if m != nil {
+		return m.Api
+	}
+	return ""
+}
+
+func (m *ListToDosResponse) GetToDos() []*ToDo {
+	if m != nil {
+		return m.ToDos
+	}
+	return nil
+}
+
+func (m *ListToDosResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ToDo)(nil), "todo.ToDo")
 	proto.RegisterType((*CreateRequest)(nil), "todo.CreateRequest")
 	proto.RegisterType((*CreateResponse)(nil), "todo.CreateResponse")
 	proto.RegisterType((*ReadRequest)(nil), "todo.ReadRequest")
 	proto.RegisterType((*ReadResponse)(nil), "todo.ReadResponse")
+	proto.RegisterType((*UpdateRequest)(nil), "todo.UpdateRequest")
+	proto.RegisterType((*UpdateResponse)(nil), "todo.UpdateResponse")
+	proto.RegisterType((*DeleteRequest)(nil), "todo.DeleteRequest")
+	proto.RegisterType((*DeleteResponse)(nil), "todo.DeleteResponse")
+	proto.RegisterType((*ReadAllRequest)(nil), "todo.ReadAllRequest")
+	proto.RegisterType((*ReadAllResponse)(nil), "todo.ReadAllResponse")
+	proto.RegisterType((*ListToDosRequest)(nil), "todo.ListToDosRequest")
+	proto.RegisterType((*ListToDosResponse)(nil), "todo.ListToDosResponse")
 }
 
 func init() { proto.RegisterFile("todo-service.proto", fileDescriptor_80b701c7b1c502fe) }
 
 var fileDescriptor_80b701c7b1c502fe = []byte{
-	// 298 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x91, 0xc1, 0x4e, 0xfa, 0x40,
-	0x10, 0xc6, 0xd3, 0xd2, 0x3f, 0xf9, 0x33, 0x55, 0xa2, 0x23, 0x87, 0xa6, 0x07, 0x6d, 0x7a, 0xe2,
-	0x42, 0x49, 0x4a, 0xe2, 0x59, 0x23, 0x4f, 0xb0, 0xf2, 0x02, 0x85, 0x1d, 0xc9, 0x26, 0xc0, 0x94,
-	0xdd, 0xc5, 0x47, 0xf0, 0xb9, 0xcd, 0xee, 0x5a, 0x53, 0x8c, 0xc4, 0x5b, 0xf7, 0x9b, 0x6f, 0x66,
-	0x7e, 0xdf, 0x14, 0xd0, 0xb2, 0xe4, 0x99, 0x21, 0xfd, 0xae, 0x36, 0x54, 0xb5, 0x9a, 0x2d, 0x63,
-	0xe2, 0xb4, 0xfc, 0x61, 0xcb, 0xbc, 0xdd, 0xd1, 0xdc, 0x6b, 0xeb, 0xd3, 0xdb, 0xdc, 0xaa, 0x3d,
-	0x19, 0xdb, 0xec, 0xdb, 0x60, 0x2b, 0x3f, 0x22, 0x48, 0x56, 0xbc, 0x64, 0x1c, 0x43, 0xac, 0x64,
-	0x16, 0x15, 0xd1, 0x74, 0x20, 0x62, 0x25, 0x71, 0x02, 0xff, 0xac, 0xb2, 0x3b, 0xca, 0xe2, 0x22,
-	0x9a, 0x8e, 0x44, 0x78, 0x60, 0x01, 0xa9, 0x24, 0xb3, 0xd1, 0xaa, 0xb5, 0x8a, 0x0f, 0xd9, 0xc0,
-	0xd7, 0xfa, 0x12, 0x3e, 0xc2, 0x7f, 0x4d, 0x7b, 0x75, 0x90, 0xa4, 0xb3, 0xa4, 0x88, 0xa6, 0x69,
-	0x9d, 0x57, 0x01, 0xa2, 0xea, 0x20, 0xaa, 0x55, 0x07, 0x21, 0xbe, 0xbd, 0xe5, 0x33, 0x5c, 0xbf,
-	0x68, 0x6a, 0x2c, 0x09, 0x3a, 0x9e, 0xc8, 0x58, 0xbc, 0x81, 0x41, 0xd3, 0x2a, 0x4f, 0x34, 0x12,
-	0xee, 0x13, 0xef, 0x21, 0xb1, 0xbc, 0x64, 0x4f, 0x94, 0xd6, 0x50, 0xb9, 0x84, 0x95, 0x83, 0x17,
-	0x5e, 0x2f, 0x6b, 0x18, 0x77, 0x23, 0x4c, 0xcb, 0x07, 0x43, 0xbf, 0xcc, 0x08, 0x31, 0xe3, 0x2e,
-	0x66, 0x39, 0x87, 0x54, 0x50, 0x23, 0x2f, 0x2f, 0xfd, 0xd9, 0xf0, 0x04, 0x57, 0xa1, 0xe1, 0xe2,
-	0x8a, 0x3f, 0x30, 0xeb, 0x23, 0xa4, 0xee, 0xf5, 0x1a, 0x7e, 0x17, 0x2e, 0x60, 0x18, 0xa8, 0xf1,
-	0x2e, 0x58, 0xcf, 0xce, 0x90, 0x4f, 0xce, 0xc5, 0xaf, 0xad, 0x33, 0x48, 0x1c, 0x05, 0xde, 0x86,
-	0x6a, 0x2f, 0x42, 0x8e, 0x7d, 0x29, 0xd8, 0xd7, 0x43, 0x7f, 0xfa, 0xc5, 0x67, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0xef, 0xa7, 0x16, 0x8b, 0x29, 0x02, 0x00, 0x00,
+	// 543 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0x56, 0x9c, 0x9f, 0x36, 0x13, 0x62, 0xda, 0x25, 0x80, 0x65, 0x21, 0xb0, 0x56, 0x1c, 0x7a,
+	0xa9, 0x23, 0x12, 0xa9, 0xa7, 0x1c, 0x9a, 0x36, 0xdc, 0x38, 0x20, 0x37, 0x3c, 0x80, 0x5b, 0x4f,
+	0xa2, 0x15, 0x89, 0xd7, 0x78, 0x37, 0x08, 0xf1, 0x00, 0x1c, 0x78, 0x3b, 0xde, 0x08, 0xed, 0xae,
+	0xd7, 0x89, 0x0d, 0x26, 0x08, 0x6e, 0xde, 0x6f, 0xe7, 0x9b, 0xf9, 0x66, 0xf6, 0x1b, 0x03, 0x91,
+	0x3c, 0xe1, 0x97, 0x02, 0xf3, 0xcf, 0xec, 0x01, 0xc3, 0x2c, 0xe7, 0x92, 0x93, 0x8e, 0xc2, 0xfc,
+	0x57, 0x6b, 0xce, 0xd7, 0x1b, 0x1c, 0x6b, 0xec, 0x7e, 0xb7, 0x1a, 0x4b, 0xb6, 0x45, 0x21, 0xe3,
+	0x6d, 0x66, 0xc2, 0xe8, 0xb7, 0x16, 0x74, 0x96, 0x7c, 0xc1, 0x89, 0x0b, 0x0e, 0x4b, 0xbc, 0x56,
+	0xd0, 0xba, 0x68, 0x47, 0x0e, 0x4b, 0xc8, 0x08, 0xba, 0x92, 0xc9, 0x0d, 0x7a, 0x4e, 0xd0, 0xba,
+	0xe8, 0x47, 0xe6, 0x40, 0x02, 0x18, 0x24, 0x28, 0x1e, 0x72, 0x96, 0x49, 0xc6, 0x53, 0xaf, 0xad,
+	0xef, 0x0e, 0x21, 0x72, 0x05, 0xa7, 0x39, 0x6e, 0x59, 0x9a, 0x60, 0xee, 0x75, 0x82, 0xd6, 0xc5,
+	0x60, 0xe2, 0x87, 0x46, 0x44, 0x68, 0x45, 0x84, 0x4b, 0x2b, 0x22, 0x2a, 0x63, 0xe9, 0x1c, 0x86,
+	0xb7, 0x39, 0xc6, 0x12, 0x23, 0xfc, 0xb4, 0x43, 0x21, 0xc9, 0x19, 0xb4, 0xe3, 0x8c, 0x69, 0x45,
+	0xfd, 0x48, 0x7d, 0x92, 0x97, 0xd0, 0x91, 0x7c, 0xc1, 0xb5, 0xa2, 0xc1, 0x04, 0x42, 0xd5, 0x61,
+	0xa8, 0xc4, 0x47, 0x1a, 0xa7, 0x13, 0x70, 0x6d, 0x0a, 0x91, 0xf1, 0x54, 0xe0, 0x6f, 0x72, 0x98,
+	0x36, 0x1d, 0xdb, 0x26, 0x1d, 0xc3, 0x20, 0xc2, 0x38, 0x69, 0x2e, 0x5a, 0x27, 0x5c, 0xc3, 0x23,
+	0x43, 0x68, 0x2c, 0x71, 0x4c, 0xe6, 0x1c, 0x86, 0x1f, 0xb2, 0xe4, 0xbf, 0x3a, 0x9d, 0x81, 0x6b,
+	0x53, 0x34, 0xca, 0xf0, 0xe0, 0x64, 0xa7, 0x63, 0xac, 0x7a, 0x7b, 0xa4, 0x6f, 0x60, 0xb8, 0xc0,
+	0x0d, 0x4a, 0xfc, 0xfb, 0xae, 0x67, 0xe0, 0x5a, 0xca, 0x9f, 0x0a, 0x26, 0x3a, 0xa6, 0x2c, 0x58,
+	0x1c, 0x29, 0x05, 0x57, 0xcd, 0x6c, 0xbe, 0xd9, 0x34, 0x56, 0xa4, 0x6f, 0xe1, 0x71, 0x19, 0xd3,
+	0x58, 0x22, 0x80, 0xae, 0xea, 0x5f, 0x78, 0x4e, 0xd0, 0xae, 0x0d, 0xc6, 0x5c, 0xd0, 0xef, 0x0e,
+	0x9c, 0xbd, 0x63, 0x42, 0x2a, 0x4c, 0x34, 0xf7, 0xe7, 0xc3, 0x69, 0x16, 0xaf, 0xf1, 0x8e, 0x7d,
+	0x35, 0x06, 0xef, 0x46, 0xe5, 0x99, 0xbc, 0x80, 0xbe, 0xfa, 0x5e, 0xf2, 0x8f, 0x68, 0x1d, 0xbe,
+	0x07, 0xc8, 0x6b, 0x18, 0xea, 0x55, 0xb8, 0xe5, 0xa9, 0x8c, 0x59, 0x2a, 0xb4, 0xc9, 0xfb, 0x51,
+	0x15, 0x24, 0x37, 0xe0, 0x5a, 0x67, 0xdf, 0xe0, 0x8a, 0xe7, 0xe8, 0x75, 0x8f, 0xee, 0x42, 0x8d,
+	0x41, 0xae, 0x61, 0x68, 0x91, 0xf9, 0x4a, 0x62, 0xee, 0xf5, 0x8e, 0xa6, 0xa8, 0x12, 0xe8, 0x16,
+	0xce, 0x0f, 0x66, 0xf1, 0xef, 0x53, 0x55, 0x4d, 0xa7, 0xf8, 0x45, 0xbe, 0xaf, 0x8d, 0xa5, 0x0a,
+	0x4e, 0x7e, 0x38, 0x30, 0x50, 0xac, 0x3b, 0xf3, 0x23, 0x22, 0x53, 0xe8, 0x99, 0x7d, 0x24, 0x4f,
+	0x4c, 0xca, 0xca, 0x82, 0xfb, 0xa3, 0x2a, 0x58, 0xc8, 0xbb, 0x84, 0x8e, 0xf2, 0x01, 0x39, 0x37,
+	0xb7, 0x07, 0xcb, 0xe9, 0x93, 0x43, 0xa8, 0x08, 0x9f, 0x42, 0xcf, 0x6c, 0x82, 0xad, 0x51, 0x59,
+	0x2d, 0x7f, 0x54, 0x05, 0xf7, 0x24, 0xe3, 0x66, 0x4b, 0xaa, 0xac, 0x83, 0x3f, 0xaa, 0x82, 0x05,
+	0xe9, 0x0a, 0x4e, 0x0a, 0x83, 0x92, 0xd1, 0x5e, 0xc8, 0xde, 0xd3, 0xfe, 0xd3, 0x1a, 0x5a, 0xf0,
+	0x66, 0xd0, 0x2f, 0x1f, 0x81, 0x3c, 0x33, 0x31, 0x75, 0x87, 0xfa, 0xcf, 0x7f, 0xc1, 0x0d, 0xfb,
+	0xbe, 0xa7, 0x5f, 0x79, 0xfa, 0x73, 0x00, 0x51, 0xe4, 0x7c, 0xa5, 0xe3, 0x05, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -323,6 +754,10 @@ const _ = grpc.SupportPackageIsVersion4
 type ToDoServiceClient interface {
 	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
 	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error)
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	ReadAll(ctx context.Context, in *ReadAllRequest, opts ...grpc.CallOption) (*ReadAllResponse, error)
+	ListToDos(ctx context.Context, in *ListToDosRequest, opts ...grpc.CallOption) (*ListToDosResponse, error)
 }
 
 type toDoServiceClient struct {
@@ -351,10 +786,50 @@ func (c *toDoServiceClient) Read(ctx context.Context, in *ReadRequest, opts ...g
 	return out, nil
 }
 
+func (c *toDoServiceClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
+	out := new(UpdateResponse)
+	err := c.cc.Invoke(ctx, "/todo.ToDoService/Update", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/todo.ToDoService/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ReadAll(ctx context.Context, in *ReadAllRequest, opts ...grpc.CallOption) (*ReadAllResponse, error) {
+	out := new(ReadAllResponse)
+	err := c.cc.Invoke(ctx, "/todo.ToDoService/ReadAll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoServiceClient) ListToDos(ctx context.Context, in *ListToDosRequest, opts ...grpc.CallOption) (*ListToDosResponse, error) {
+	out := new(ListToDosResponse)
+	err := c.cc.Invoke(ctx, "/todo.ToDoService/ListToDos", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ToDoServiceServer is the server API for ToDoService service.
 type ToDoServiceServer interface {
 	Create(context.Context, *CreateRequest) (*CreateResponse, error)
 	Read(context.Context, *ReadRequest) (*ReadResponse, error)
+	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	ReadAll(context.Context, *ReadAllRequest) (*ReadAllResponse, error)
+	ListToDos(context.Context, *ListToDosRequest) (*ListToDosResponse, error)
 }
 
 // UnimplementedToDoServiceServer can be embedded to have forward compatible implementations.
@@ -367,6 +842,18 @@ func (*UnimplementedToDoServiceServer) Create(ctx context.Context, req *CreateRe
 func (*UnimplementedToDoServiceServer) Read(ctx context.Context, req *ReadRequest) (*ReadResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
 }
+func (*UnimplementedToDoServiceServer) Update(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (*UnimplementedToDoServiceServer) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedToDoServiceServer) ReadAll(ctx context.Context, req *ReadAllRequest) (*ReadAllResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadAll not implemented")
+}
+func (*UnimplementedToDoServiceServer) ListToDos(ctx context.Context, req *ListToDosRequest) (*ListToDosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListToDos not implemented")
+}
 
 func RegisterToDoServiceServer(s *grpc.Server, srv ToDoServiceServer) {
 	s.RegisterService(&_ToDoService_serviceDesc, srv)
@@ -408,6 +895,78 @@ func _ToDoService_Read_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ToDoService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.ToDoService/Update",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.ToDoService/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ReadAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ReadAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.ToDoService/ReadAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ReadAll(ctx, req.(*ReadAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoService_ListToDos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListToDosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoServiceServer).ListToDos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo.ToDoService/ListToDos",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoServiceServer).ListToDos(ctx, req.(*ListToDosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ToDoService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "todo.ToDoService",
 	HandlerType: (*ToDoServiceServer)(nil),
@@ -420,6 +979,22 @@ var _ToDoService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Read",
 			Handler:    _ToDoService_Read_Handler,
 		},
+		{
+			MethodName: "Update",
+			Handler:    _ToDoService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _ToDoService_Delete_Handler,
+		},
+		{
+			MethodName: "ReadAll",
+			Handler:    _ToDoService_ReadAll_Handler,
+		},
+		{
+			MethodName: "ListToDos",
+			Handler:    _ToDoService_ListToDos_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "todo-service.proto",
@@ -2,39 +2,112 @@ package todo
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
-	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sarulabs/di"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
-	"grpoc/pkg/api/v1"
+	"grpoc/modules"
+	mymodel "grpoc/modules/model"
 )
 
+// testTenant is the tenant of the caller in every test
+const testTenant = "tenant-1"
+
+// newServer builds the service on top of a container holding the mocked database and a silent logger
+func newServer(t *testing.T, db *sql.DB) ToDoServiceServer {
+	builder, err := di.NewBuilder()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the container builder", err)
+	}
+
+	err = builder.Add(di.Def{
+		Name:  modules.InstDatabase,
+		Scope: di.App,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return db, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when registering the database", err)
+	}
+
+	err = builder.Add(di.Def{
+		Name:  modules.InstLogger,
+		Scope: di.App,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return zap.NewNop(), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when registering the logger", err)
+	}
+
+	ctn := builder.Build()
+
+	return NewToDoServiceServer(&ctn)
+}
+
+func Test_pageTokenKey(t *testing.T) {
+	builder, err := di.NewBuilder()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating the container builder", err)
+	}
+
+	err = builder.Add(di.Def{
+		Name:  modules.InstConfig,
+		Scope: di.App,
+		Build: func(ctn di.Container) (interface{}, error) {
+			return &modules.AppConfig{Pagination: modules.PaginationSettings{Secret: "page-secret"}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when registering the config", err)
+	}
+	ctn := builder.Build()
+
+	if got := string(pageTokenKey(&ctn, zap.NewNop())); got != "page-secret" {
+		t.Errorf("pageTokenKey() = %q, want the secret of the typed config", got)
+	}
+
+	// without a config the key is random
+	builder, _ = di.NewBuilder()
+	none := builder.Build()
+	if got := pageTokenKey(&none, zap.NewNop()); len(got) != 32 {
+		t.Errorf("pageTokenKey() = %d bytes, want a random 32 byte key", len(got))
+	}
+}
+
 func Test_toDoServiceServer_Create(t *testing.T) {
-	ctx := context.Background()
+	ctx := mymodel.WithTenant(context.Background(), testTenant)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
-	s := NewToDoServiceServer(db)
-	tm := time.Now().In(time.UTC)
+	s := newServer(t, db)
+	tm := time.Now().In(time.UTC).Truncate(time.Second)
 	reminder, _ := ptypes.TimestampProto(tm)
 
 	type args struct {
 		ctx context.Context
-		req *v1.CreateRequest
+		req *CreateRequest
 	}
 	tests := []struct {
 		name    string
-		s       v1.ToDoServiceServer
+		s       ToDoServiceServer
 		args    args
 		mock    func()
-		want    *v1.CreateResponse
+		want    *CreateResponse
 		wantErr bool
 	}{
 		{
@@ -42,9 +115,9 @@ func Test_toDoServiceServer_Create(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.CreateRequest{
+				req: &CreateRequest{
 					Api: "v1",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Title:       "title",
 						Description: "description",
 						Reminder:    reminder,
@@ -52,10 +125,12 @@ func Test_toDoServiceServer_Create(t *testing.T) {
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("INSERT INTO ToDo").WithArgs("title", "description", tm).
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO ToDo").WithArgs("title", "description", tm.Format(mymodel.SQLDatetime), testTenant).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
-			want: &v1.CreateResponse{
+			want: &CreateResponse{
 				Api: "v1",
 				Id:  1,
 			},
@@ -65,9 +140,9 @@ func Test_toDoServiceServer_Create(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.CreateRequest{
+				req: &CreateRequest{
 					Api: "v1000",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Title:       "title",
 						Description: "description",
 						Reminder: &timestamp.Timestamp{
@@ -80,14 +155,26 @@ func Test_toDoServiceServer_Create(t *testing.T) {
 			mock:    func() {},
 			wantErr: true,
 		},
+		{
+			name: "Missing ToDo",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &CreateRequest{
+					Api: "v1",
+				},
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
 		{
 			name: "Invalid Reminder field format",
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.CreateRequest{
+				req: &CreateRequest{
 					Api: "v1",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Title:       "title",
 						Description: "description",
 						Reminder: &timestamp.Timestamp{
@@ -105,9 +192,9 @@ func Test_toDoServiceServer_Create(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.CreateRequest{
+				req: &CreateRequest{
 					Api: "v1",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Title:       "title",
 						Description: "description",
 						Reminder:    reminder,
@@ -115,8 +202,10 @@ func Test_toDoServiceServer_Create(t *testing.T) {
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("INSERT INTO ToDo").WithArgs("title", "description", tm).
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO ToDo").WithArgs("title", "description", tm.Format(mymodel.SQLDatetime), testTenant).
 					WillReturnError(errors.New("INSERT failed"))
+				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
@@ -125,9 +214,9 @@ func Test_toDoServiceServer_Create(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.CreateRequest{
+				req: &CreateRequest{
 					Api: "v1",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Title:       "title",
 						Description: "description",
 						Reminder:    reminder,
@@ -135,8 +224,10 @@ func Test_toDoServiceServer_Create(t *testing.T) {
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("INSERT INTO ToDo").WithArgs("title", "description", tm).
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO ToDo").WithArgs("title", "description", tm.Format(mymodel.SQLDatetime), testTenant).
 					WillReturnResult(sqlmock.NewErrorResult(errors.New("LastInsertId failed")))
+				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
@@ -152,31 +243,34 @@ func Test_toDoServiceServer_Create(t *testing.T) {
 			if err == nil && !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("toDoServiceServer.Create() = %v, want %v", got, tt.want)
 			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("toDoServiceServer.Create() %v", err)
+			}
 		})
 	}
 }
 
 func Test_toDoServiceServer_Read(t *testing.T) {
-	ctx := context.Background()
+	ctx := mymodel.WithTenant(context.Background(), testTenant)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
-	s := NewToDoServiceServer(db)
-	tm := time.Now().In(time.UTC)
+	s := newServer(t, db)
+	tm := time.Now().In(time.UTC).Truncate(time.Second)
 	reminder, _ := ptypes.TimestampProto(tm)
 
 	type args struct {
 		ctx context.Context
-		req *v1.ReadRequest
+		req *ReadRequest
 	}
 	tests := []struct {
 		name    string
-		s       v1.ToDoServiceServer
+		s       ToDoServiceServer
 		args    args
 		mock    func()
-		want    *v1.ReadResponse
+		want    *ReadResponse
 		wantErr bool
 	}{
 		{
@@ -184,19 +278,19 @@ func Test_toDoServiceServer_Read(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.ReadRequest{
+				req: &ReadRequest{
 					Api: "v1",
 					Id:  1,
 				},
 			},
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"ID", "Title", "Description", "Reminder"}).
-					AddRow(1, "title", "description", tm)
-				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(1).WillReturnRows(rows)
+				rows := sqlmock.NewRows([]string{"id", "title", "description", "reminder"}).
+					AddRow(1, "title", "description", tm.Format(mymodel.SQLDatetime))
+				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(testTenant, 1).WillReturnRows(rows)
 			},
-			want: &v1.ReadResponse{
+			want: &ReadResponse{
 				Api: "v1",
-				ToDo: &v1.ToDo{
+				ToDo: &ToDo{
 					Id:          1,
 					Title:       "title",
 					Description: "description",
@@ -209,8 +303,8 @@ func Test_toDoServiceServer_Read(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.ReadRequest{
-					Api: "v1",
+				req: &ReadRequest{
+					Api: "v1000",
 					Id:  1,
 				},
 			},
@@ -222,13 +316,13 @@ func Test_toDoServiceServer_Read(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.ReadRequest{
+				req: &ReadRequest{
 					Api: "v1",
 					Id:  1,
 				},
 			},
 			mock: func() {
-				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(1).
+				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(testTenant, 1).
 					WillReturnError(errors.New("SELECT failed"))
 			},
 			wantErr: true,
@@ -238,14 +332,14 @@ func Test_toDoServiceServer_Read(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.ReadRequest{
+				req: &ReadRequest{
 					Api: "v1",
 					Id:  1,
 				},
 			},
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"ID", "Title", "Description", "Reminder"})
-				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(1).WillReturnRows(rows)
+				rows := sqlmock.NewRows([]string{"id", "title", "description", "reminder"})
+				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(testTenant, 1).WillReturnRows(rows)
 			},
 			wantErr: true,
 		},
@@ -266,27 +360,103 @@ func Test_toDoServiceServer_Read(t *testing.T) {
 	}
 }
 
-func Test_toDoServiceServer_Update(t *testing.T) {
-	ctx := context.Background()
+func Test_toDoServiceServer_Read_ContextDone(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := newServer(t, db)
+
+	cancelled, cancel := context.WithCancel(mymodel.WithTenant(context.Background(), testTenant))
+	cancel()
+	expired, cancel := context.WithDeadline(mymodel.WithTenant(context.Background(), testTenant), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{name: "Canceled", ctx: cancelled, want: codes.Canceled},
+		{name: "Deadline exceeded", ctx: expired, want: codes.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Read(tt.ctx, &ReadRequest{Api: "v1", Id: 1})
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("toDoServiceServer.Read() code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_toDoServiceServer_NoTenant(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
-	s := NewToDoServiceServer(db)
+	s := newServer(t, db)
+
+	// a server without the auth interceptor has no tenant in the context
+	ctx := context.Background()
 	tm := time.Now().In(time.UTC)
 	reminder, _ := ptypes.TimestampProto(tm)
 
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "Create", call: func() error {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+			_, err := s.Create(ctx, &CreateRequest{Api: "v1", ToDo: &ToDo{Title: "title", Reminder: reminder}})
+			return err
+		}},
+		{name: "Read", call: func() error {
+			_, err := s.Read(ctx, &ReadRequest{Api: "v1", Id: 1})
+			return err
+		}},
+		{name: "ReadAll", call: func() error {
+			_, err := s.ReadAll(ctx, &ReadAllRequest{Api: "v1"})
+			return err
+		}},
+		{name: "ListToDos", call: func() error {
+			_, err := s.ListToDos(ctx, &ListToDosRequest{Api: "v1"})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != codes.Unauthenticated {
+				t.Errorf("toDoServiceServer.%s() code = %v, want %v", tt.name, got, codes.Unauthenticated)
+			}
+		})
+	}
+}
+
+func Test_toDoServiceServer_Update(t *testing.T) {
+	ctx := mymodel.WithTenant(context.Background(), testTenant)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := newServer(t, db)
+	tm := time.Now().In(time.UTC).Truncate(time.Second)
+	reminder, _ := ptypes.TimestampProto(tm)
+
 	type args struct {
 		ctx context.Context
-		req *v1.UpdateRequest
+		req *UpdateRequest
 	}
 	tests := []struct {
 		name    string
-		s       v1.ToDoServiceServer
+		s       ToDoServiceServer
 		args    args
 		mock    func()
-		want    *v1.UpdateResponse
+		want    *UpdateResponse
 		wantErr bool
 	}{
 		{
@@ -294,9 +464,9 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.UpdateRequest{
+				req: &UpdateRequest{
 					Api: "v1",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Id:          1,
 						Title:       "new title",
 						Description: "new description",
@@ -305,10 +475,10 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("UPDATE ToDo").WithArgs("new title", "new description", tm, 1).
+				mock.ExpectExec("UPDATE ToDo").WithArgs("new description", tm.Format(mymodel.SQLDatetime), "new title", testTenant, 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
-			want: &v1.UpdateResponse{
+			want: &UpdateResponse{
 				Api:     "v1",
 				Updated: 1,
 			},
@@ -318,9 +488,9 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.UpdateRequest{
-					Api: "v1",
-					ToDo: &v1.ToDo{
+				req: &UpdateRequest{
+					Api: "v1000",
+					ToDo: &ToDo{
 						Id:          1,
 						Title:       "new title",
 						Description: "new description",
@@ -336,9 +506,9 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.UpdateRequest{
+				req: &UpdateRequest{
 					Api: "v1",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Id:          1,
 						Title:       "new title",
 						Description: "new description",
@@ -357,9 +527,9 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.UpdateRequest{
+				req: &UpdateRequest{
 					Api: "v1",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Id:          1,
 						Title:       "new title",
 						Description: "new description",
@@ -368,7 +538,7 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("UPDATE ToDo").WithArgs("new title", "new description", tm, 1).
+				mock.ExpectExec("UPDATE ToDo").WithArgs("new description", tm.Format(mymodel.SQLDatetime), "new title", testTenant, 1).
 					WillReturnError(errors.New("UPDATE failed"))
 			},
 			wantErr: true,
@@ -378,9 +548,9 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.UpdateRequest{
+				req: &UpdateRequest{
 					Api: "v1",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Id:          1,
 						Title:       "new title",
 						Description: "new description",
@@ -389,7 +559,7 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("UPDATE ToDo").WithArgs("new title", "new description", tm, 1).
+				mock.ExpectExec("UPDATE ToDo").WithArgs("new description", tm.Format(mymodel.SQLDatetime), "new title", testTenant, 1).
 					WillReturnResult(sqlmock.NewErrorResult(errors.New("RowsAffected failed")))
 			},
 			wantErr: true,
@@ -399,9 +569,59 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.UpdateRequest{
+				req: &UpdateRequest{
+					Api: "v1",
+					ToDo: &ToDo{
+						Id:          1,
+						Title:       "new title",
+						Description: "new description",
+						Reminder:    reminder,
+					},
+				},
+			},
+			mock: func() {
+				mock.ExpectExec("UPDATE ToDo").WithArgs("new description", tm.Format(mymodel.SQLDatetime), "new title", testTenant, 1).
+					WillReturnResult(sqlmock.NewResult(1, 0))
+				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(testTenant, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "reminder"}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unchanged",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &UpdateRequest{
+					Api: "v1",
+					ToDo: &ToDo{
+						Id:          1,
+						Title:       "new title",
+						Description: "new description",
+						Reminder:    reminder,
+					},
+				},
+			},
+			mock: func() {
+				mock.ExpectExec("UPDATE ToDo").WithArgs("new description", tm.Format(mymodel.SQLDatetime), "new title", testTenant, 1).
+					WillReturnResult(sqlmock.NewResult(1, 0))
+				rows := sqlmock.NewRows([]string{"id", "title", "description", "reminder"}).
+					AddRow(1, "new title", "new description", tm.Format(mymodel.SQLDatetime))
+				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(testTenant, 1).WillReturnRows(rows)
+			},
+			want: &UpdateResponse{
+				Api:     "v1",
+				Updated: 1,
+			},
+		},
+		{
+			name: "SELECT of the unchanged row failed",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &UpdateRequest{
 					Api: "v1",
-					ToDo: &v1.ToDo{
+					ToDo: &ToDo{
 						Id:          1,
 						Title:       "new title",
 						Description: "new description",
@@ -410,8 +630,10 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("UPDATE ToDo").WithArgs("new title", "new description", tm, 1).
+				mock.ExpectExec("UPDATE ToDo").WithArgs("new description", tm.Format(mymodel.SQLDatetime), "new title", testTenant, 1).
 					WillReturnResult(sqlmock.NewResult(1, 0))
+				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(testTenant, 1).
+					WillReturnError(errors.New("SELECT failed"))
 			},
 			wantErr: true,
 		},
@@ -432,24 +654,24 @@ func Test_toDoServiceServer_Update(t *testing.T) {
 }
 
 func Test_toDoServiceServer_Delete(t *testing.T) {
-	ctx := context.Background()
+	ctx := mymodel.WithTenant(context.Background(), testTenant)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
-	s := NewToDoServiceServer(db)
+	s := newServer(t, db)
 
 	type args struct {
 		ctx context.Context
-		req *v1.DeleteRequest
+		req *DeleteRequest
 	}
 	tests := []struct {
 		name    string
-		s       v1.ToDoServiceServer
+		s       ToDoServiceServer
 		args    args
 		mock    func()
-		want    *v1.DeleteResponse
+		want    *DeleteResponse
 		wantErr bool
 	}{
 		{
@@ -457,16 +679,16 @@ func Test_toDoServiceServer_Delete(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.DeleteRequest{
+				req: &DeleteRequest{
 					Api: "v1",
 					Id:  1,
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("DELETE FROM ToDo").WithArgs(1).
+				mock.ExpectExec("DELETE FROM ToDo").WithArgs(testTenant, 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
-			want: &v1.DeleteResponse{
+			want: &DeleteResponse{
 				Api:     "v1",
 				Deleted: 1,
 			},
@@ -476,8 +698,8 @@ func Test_toDoServiceServer_Delete(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.DeleteRequest{
-					Api: "v1",
+				req: &DeleteRequest{
+					Api: "v1000",
 					Id:  1,
 				},
 			},
@@ -489,13 +711,13 @@ func Test_toDoServiceServer_Delete(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.DeleteRequest{
+				req: &DeleteRequest{
 					Api: "v1",
 					Id:  1,
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("DELETE FROM ToDo").WithArgs(1).
+				mock.ExpectExec("DELETE FROM ToDo").WithArgs(testTenant, 1).
 					WillReturnError(errors.New("DELETE failed"))
 			},
 			wantErr: true,
@@ -505,13 +727,13 @@ func Test_toDoServiceServer_Delete(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.DeleteRequest{
+				req: &DeleteRequest{
 					Api: "v1",
 					Id:  1,
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("DELETE FROM ToDo").WithArgs(1).
+				mock.ExpectExec("DELETE FROM ToDo").WithArgs(testTenant, 1).
 					WillReturnResult(sqlmock.NewErrorResult(errors.New("RowsAffected failed")))
 			},
 			wantErr: true,
@@ -521,13 +743,13 @@ func Test_toDoServiceServer_Delete(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.DeleteRequest{
+				req: &DeleteRequest{
 					Api: "v1",
 					Id:  1,
 				},
 			},
 			mock: func() {
-				mock.ExpectExec("DELETE FROM ToDo").WithArgs(1).
+				mock.ExpectExec("DELETE FROM ToDo").WithArgs(testTenant, 1).
 					WillReturnResult(sqlmock.NewResult(1, 0))
 			},
 			wantErr: true,
@@ -549,28 +771,28 @@ func Test_toDoServiceServer_Delete(t *testing.T) {
 }
 
 func Test_toDoServiceServer_ReadAll(t *testing.T) {
-	ctx := context.Background()
+	ctx := mymodel.WithTenant(context.Background(), testTenant)
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
-	s := NewToDoServiceServer(db)
-	tm1 := time.Now().In(time.UTC)
+	s := newServer(t, db)
+	tm1 := time.Now().In(time.UTC).Truncate(time.Second)
 	reminder1, _ := ptypes.TimestampProto(tm1)
-	tm2 := time.Now().In(time.UTC)
+	tm2 := time.Now().In(time.UTC).Truncate(time.Second)
 	reminder2, _ := ptypes.TimestampProto(tm2)
 
 	type args struct {
 		ctx context.Context
-		req *v1.ReadAllRequest
+		req *ReadAllRequest
 	}
 	tests := []struct {
 		name    string
-		s       v1.ToDoServiceServer
+		s       ToDoServiceServer
 		args    args
 		mock    func()
-		want    *v1.ReadAllResponse
+		want    *ReadAllResponse
 		wantErr bool
 	}{
 		{
@@ -578,19 +800,19 @@ func Test_toDoServiceServer_ReadAll(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.ReadAllRequest{
+				req: &ReadAllRequest{
 					Api: "v1",
 				},
 			},
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"ID", "Title", "Description", "Reminder"}).
-					AddRow(1, "title 1", "description 1", tm1).
-					AddRow(2, "title 2", "description 2", tm2)
-				mock.ExpectQuery("SELECT (.+) FROM ToDo").WillReturnRows(rows)
+				rows := sqlmock.NewRows([]string{"id", "title", "description", "reminder"}).
+					AddRow(1, "title 1", "description 1", tm1.Format(mymodel.SQLDatetime)).
+					AddRow(2, "title 2", "description 2", tm2.Format(mymodel.SQLDatetime))
+				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(testTenant).WillReturnRows(rows)
 			},
-			want: &v1.ReadAllResponse{
+			want: &ReadAllResponse{
 				Api: "v1",
-				ToDos: []*v1.ToDo{
+				ToDos: []*ToDo{
 					{
 						Id:          1,
 						Title:       "title 1",
@@ -611,17 +833,17 @@ func Test_toDoServiceServer_ReadAll(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.ReadAllRequest{
+				req: &ReadAllRequest{
 					Api: "v1",
 				},
 			},
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"ID", "Title", "Description", "Reminder"})
-				mock.ExpectQuery("SELECT (.+) FROM ToDo").WillReturnRows(rows)
+				rows := sqlmock.NewRows([]string{"id", "title", "description", "reminder"})
+				mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(testTenant).WillReturnRows(rows)
 			},
-			want: &v1.ReadAllResponse{
+			want: &ReadAllResponse{
 				Api:   "v1",
-				ToDos: []*v1.ToDo{},
+				ToDos: []*ToDo{},
 			},
 		},
 		{
@@ -629,8 +851,8 @@ func Test_toDoServiceServer_ReadAll(t *testing.T) {
 			s:    s,
 			args: args{
 				ctx: ctx,
-				req: &v1.ReadAllRequest{
-					Api: "v1",
+				req: &ReadAllRequest{
+					Api: "v1000",
 				},
 			},
 			mock:    func() {},
@@ -650,4 +872,185 @@ func Test_toDoServiceServer_ReadAll(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func Test_toDoServiceServer_ListToDos(t *testing.T) {
+	ctx := mymodel.WithTenant(context.Background(), testTenant)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := newServer(t, db)
+	tm := time.Now().In(time.UTC).Truncate(time.Second)
+	reminder, _ := ptypes.TimestampProto(tm)
+
+	// first page, used to issue a token for the follow up requests
+	rows := sqlmock.NewRows([]string{"id", "title", "description", "reminder"}).
+		AddRow(1, "title 1", "description 1", tm.Format(mymodel.SQLDatetime)).
+		AddRow(2, "title 2", "description 2", tm.Format(mymodel.SQLDatetime))
+	mock.ExpectQuery(`SELECT (.+) FROM ToDo WHERE tenant = \? AND \(title LIKE \?\) ORDER BY reminder ASC,id ASC LIMIT 0,2`).
+		WithArgs(testTenant, `%50\%%`).WillReturnRows(rows)
+
+	first, err := s.ListToDos(ctx, &ListToDosRequest{Api: "v1", PageSize: 1, TitleContains: "50%"})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when listing the first page", err)
+	}
+	if len(first.ToDos) != 1 || first.NextPageToken == "" {
+		t.Fatalf("toDoServiceServer.ListToDos() = %v, want one todo and a next page token", first)
+	}
+
+	type args struct {
+		ctx context.Context
+		req *ListToDosRequest
+	}
+	tests := []struct {
+		name    string
+		s       ToDoServiceServer
+		args    args
+		mock    func()
+		want    *ListToDosResponse
+		wantErr bool
+	}{
+		{
+			name: "Next page",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &ListToDosRequest{
+					Api:           "v1",
+					PageSize:      1,
+					PageToken:     first.NextPageToken,
+					TitleContains: "50%",
+				},
+			},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "title", "description", "reminder"}).
+					AddRow(2, "title 2", "description 2", tm.Format(mymodel.SQLDatetime))
+				mock.ExpectQuery(`SELECT (.+) FROM ToDo WHERE tenant = \? AND \(title LIKE \? AND \(reminder > \? OR \(reminder = \? AND id > \?\)\)\)`).
+					WithArgs(testTenant, `%50\%%`, tm.Format(mymodel.SQLDatetime), tm.Format(mymodel.SQLDatetime), 1).WillReturnRows(rows)
+			},
+			want: &ListToDosResponse{
+				Api: "v1",
+				ToDos: []*ToDo{
+					{
+						Id:          2,
+						Title:       "title 2",
+						Description: "description 2",
+						Reminder:    reminder,
+					},
+				},
+			},
+		},
+		{
+			name: "Page size capped",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &ListToDosRequest{
+					Api:      "v1",
+					PageSize: 1000,
+				},
+			},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "title", "description", "reminder"})
+				mock.ExpectQuery(`SELECT (.+) FROM ToDo WHERE tenant = \? ORDER BY reminder ASC,id ASC LIMIT 0,51`).WithArgs(testTenant).WillReturnRows(rows)
+			},
+			want: &ListToDosResponse{
+				Api:   "v1",
+				ToDos: []*ToDo{},
+			},
+		},
+		{
+			name: "Token for other filters",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &ListToDosRequest{
+					Api:           "v1",
+					PageSize:      1,
+					PageToken:     first.NextPageToken,
+					TitleContains: "other",
+				},
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name: "Tampered token",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &ListToDosRequest{
+					Api:       "v1",
+					PageToken: "x" + first.NextPageToken,
+				},
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name: "Negative page size",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &ListToDosRequest{
+					Api:      "v1",
+					PageSize: -1,
+				},
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
+		{
+			name: "Unsupported API",
+			s:    s,
+			args: args{
+				ctx: ctx,
+				req: &ListToDosRequest{
+					Api: "v1000",
+				},
+			},
+			mock:    func() {},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := tt.s.ListToDos(tt.args.ctx, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("toDoServiceServer.ListToDos() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("toDoServiceServer.ListToDos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_toDoServiceServer_ListToDos_invalidReminder(t *testing.T) {
+	ctx := mymodel.WithTenant(context.Background(), testTenant)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	s := newServer(t, db)
+	tm := time.Now().In(time.UTC).Truncate(time.Second)
+
+	for _, reminder := range []string{"tomorrow", "0000-00-00 00:00:00", "0001-01-01T00:00:00Z"} {
+		t.Run(reminder, func(t *testing.T) {
+			// the last todo of the page is the one the cursor is issued for
+			rows := sqlmock.NewRows([]string{"id", "title", "description", "reminder"}).
+				AddRow(1, "title 1", "description 1", reminder).
+				AddRow(2, "title 2", "description 2", tm.Format(mymodel.SQLDatetime))
+			mock.ExpectQuery("SELECT (.+) FROM ToDo").WithArgs(testTenant).WillReturnRows(rows)
+
+			_, err := s.ListToDos(ctx, &ListToDosRequest{Api: "v1", PageSize: 1})
+			if status.Code(err) != codes.Internal {
+				t.Errorf("toDoServiceServer.ListToDos() error = %v, want %s", err, codes.Internal)
+			}
+		})
+	}
+}